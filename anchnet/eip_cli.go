@@ -5,6 +5,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -23,8 +24,8 @@ func execDescribeEips(cmd *cobra.Command, args []string, client *anchnet.Client,
 	request := anchnet.DescribeEipsRequest{
 		EipIDs: strings.Split(args[0], ","),
 	}
-	var response anchnet.DescribeEipsResponse
-	sendResult(&response, out, "DescribeEips", response.Code, client.SendRequest(request, &response))
+	response, err := client.DescribeEips(context.Background(), &request)
+	sendResult(response, out, "DescribeEips", err)
 }
 
 func execReleaseEips(cmd *cobra.Command, args []string, client *anchnet.Client, out io.Writer) {
@@ -36,6 +37,6 @@ func execReleaseEips(cmd *cobra.Command, args []string, client *anchnet.Client,
 	request := anchnet.ReleaseEipsRequest{
 		EipIDs: strings.Split(args[0], ","),
 	}
-	var response anchnet.ReleaseEipsResponse
-	sendResult(&response, out, "ReleaseEips", response.Code, client.SendRequest(request, &response))
+	response, err := client.ReleaseEips(context.Background(), &request)
+	sendResult(response, out, "ReleaseEips", err)
 }
@@ -5,6 +5,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -24,8 +25,8 @@ func execCaptureInstance(cmd *cobra.Command, args []string, client *anchnet.Clie
 		ImageName: args[0],
 		Instance:  args[1],
 	}
-	var response anchnet.CaptureInstanceResponse
-	sendResult(&response, out, "CaptureInstance", response.Code, client.SendRequest(request, &response))
+	response, err := client.CaptureInstance(context.Background(), &request)
+	sendResult(response, out, "CaptureInstance", err)
 }
 
 func execGrantImageToUsers(cmd *cobra.Command, args []string, client *anchnet.Client, out io.Writer) {
@@ -38,8 +39,8 @@ func execGrantImageToUsers(cmd *cobra.Command, args []string, client *anchnet.Cl
 		ImageID: args[0],
 		UserIDs: strings.Split(args[1], ","),
 	}
-	var response anchnet.GrantImageToUsersResponse
-	sendResult(&response, out, "GrantImageToUsers", response.Code, client.SendRequest(request, &response))
+	response, err := client.GrantImageToUsers(context.Background(), &request)
+	sendResult(response, out, "GrantImageToUsers", err)
 }
 
 func execRevokeImageFromUsers(cmd *cobra.Command, args []string, client *anchnet.Client, out io.Writer) {
@@ -52,8 +53,8 @@ func execRevokeImageFromUsers(cmd *cobra.Command, args []string, client *anchnet
 		ImageIDs: []string{args[0]},
 		UserIDs:  strings.Split(args[1], ","),
 	}
-	var response anchnet.RevokeImageFromUsersResponse
-	sendResult(&response, out, "RevokeImageToUsers", response.Code, client.SendRequest(request, &response))
+	response, err := client.RevokeImageFromUsers(context.Background(), &request)
+	sendResult(response, out, "RevokeImageToUsers", err)
 }
 
 func execDescribeImageUsers(cmd *cobra.Command, args []string, client *anchnet.Client, out io.Writer) {
@@ -65,6 +66,6 @@ func execDescribeImageUsers(cmd *cobra.Command, args []string, client *anchnet.C
 	request := anchnet.DescribeImageUsersRequest{
 		ImageIDs: []string{args[0]},
 	}
-	var response anchnet.DescribeImageUsersResponse
-	sendResult(&response, out, "DescribeImageUsers", response.Code, client.SendRequest(request, &response))
+	response, err := client.DescribeImageUsers(context.Background(), &request)
+	sendResult(response, out, "DescribeImageUsers", err)
 }
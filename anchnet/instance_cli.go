@@ -5,16 +5,36 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	anchnet "github.com/caicloud/anchnet-go"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 func execRunInstance(cmd *cobra.Command, args []string, client *anchnet.Client, out io.Writer) {
+	if path := getFlagString(cmd, "filename"); path != "" {
+		// The spec describes everything; other flags or args would be silently ignored.
+		var others []string
+		cmd.LocalFlags().VisitAll(func(flag *pflag.Flag) {
+			if flag.Changed && flag.Name != "filename" {
+				others = append(others, "--"+flag.Name)
+			}
+		})
+		if len(args) > 0 || len(others) > 0 {
+			fmt.Fprintf(os.Stderr, "Flag --filename can't be used with instance name or other flags, got %v\n", append(args, others...))
+			os.Exit(1)
+		}
+		execRunInstanceSpec(path, client, out)
+		return
+	}
 	if len(args) == 0 {
 		fmt.Fprintln(os.Stderr, "Instance name required")
 		os.Exit(1)
@@ -24,32 +44,153 @@ func execRunInstance(cmd *cobra.Command, args []string, client *anchnet.Client,
 	amount := getFlagInt(cmd, "amount")
 	memory := getFlagInt(cmd, "memory")
 	passwd := getFlagString(cmd, "passwd")
+	keypair := getFlagString(cmd, "keypair")
 	bandwidth := getFlagInt(cmd, "bandwidth")
 	image_id := getFlagString(cmd, "image-id")
 	ip_group := getFlagString(cmd, "ip-group")
+	user_data_file := getFlagString(cmd, "user-data-file")
+	disks := getFlagString(cmd, "disks")
+	disk_ids := getFlagString(cmd, "disk-ids")
+	vxnet_name := getFlagString(cmd, "vxnet-name")
+	vxnet_ids := getFlagString(cmd, "vxnet-ids")
+	eip_id := getFlagString(cmd, "eip-id")
+	no_public_ip := getFlagBool(cmd, "no-public-ip")
+
+	login_mode := anchnet.LoginModePwd
+	switch {
+	case keypair != "" && passwd != "":
+		fmt.Fprintln(os.Stderr, "Password and keypair can't be used together")
+		os.Exit(1)
+	case keypair != "":
+		login_mode = anchnet.LoginModeKeypair
+	case passwd == "":
+		fmt.Fprintln(os.Stderr, "Password or keypair required")
+		os.Exit(1)
+	}
+	var user_data []byte
+	if user_data_file != "" {
+		var err error
+		if user_data, err = ioutil.ReadFile(user_data_file); err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading user data file %v: %v\n", user_data_file, err)
+			os.Exit(1)
+		}
+	}
 
 	request := anchnet.RunInstancesRequest{
 		Product: anchnet.RunInstancesProduct{
 			Cloud: anchnet.RunInstancesCloud{
 				VM: anchnet.RunInstancesVM{
 					Name:      args[0],
-					LoginMode: anchnet.LoginModePwd,
+					LoginMode: login_mode,
 					Mem:       memory,
 					Cpu:       cpu,
 					Password:  passwd,
+					KeyPairID: keypair,
+					UserData:  user_data,
 					ImageID:   image_id,
 				},
-				Net0: true, // Create public network
-				IP: anchnet.RunInstancesIP{
-					IPGroup:   anchnet.IPGroupType(ip_group),
-					Bandwidth: bandwidth,
-				},
 				Amount: amount,
 			},
 		},
 	}
-	var response anchnet.RunInstancesResponse
-	sendResult(&response, out, "RunInstance", response.Code, client.SendRequest(request, &response))
+	cloud := &request.Product.Cloud
+
+	if disks != "" {
+		for _, disk := range strings.Split(disks, ",") {
+			hd, err := parseDisk(disk)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			cloud.HD = append(cloud.HD, hd)
+		}
+	}
+	if disk_ids != "" {
+		cloud.HD = append(cloud.HD, anchnet.RunInstancesHardDisk{HdIDs: strings.Split(disk_ids, ",")})
+	}
+
+	if vxnet_name != "" {
+		cloud.Net1 = append(cloud.Net1, anchnet.RunInstancesNet1{VxnetName: vxnet_name, Checked: true})
+	}
+	if vxnet_ids != "" {
+		cloud.Net1 = append(cloud.Net1, anchnet.RunInstancesNet1{VxnetIDs: strings.Split(vxnet_ids, ",")})
+	}
+
+	switch {
+	case no_public_ip && eip_id != "":
+		fmt.Fprintln(os.Stderr, "Flags --no-public-ip and --eip-id can't be used together")
+		os.Exit(1)
+	case no_public_ip:
+	case eip_id != "":
+		cloud.Net0 = true // Join public network
+		cloud.IP = anchnet.RunInstancesIP{EipID: eip_id}
+	default:
+		cloud.Net0 = true // Create public network
+		cloud.IP = anchnet.RunInstancesIP{
+			IPGroup:   anchnet.IPGroupType(ip_group),
+			Bandwidth: bandwidth,
+		}
+	}
+
+	response, err := client.RunInstances(context.Background(), &request)
+	sendResult(response, out, "RunInstance", err)
+}
+
+// execRunInstanceSpec runs instances declared in a spec file, see RunInstancesSpec.
+// If the spec has a security group, it waits for the instances to run and applies
+// the group.
+// specWaitTimeout bounds every job wait of runinstance --filename.
+const specWaitTimeout = 10 * time.Minute
+
+func execRunInstanceSpec(path string, client *anchnet.Client, out io.Writer) {
+	spec, err := anchnet.LoadRunInstancesSpec(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading spec %v: %v\n", path, err)
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+	response, err := client.RunInstances(ctx, spec.Request())
+	if err != nil || spec.SecurityGroupID == "" {
+		sendResult(response, out, "RunInstance", err)
+		return
+	}
+	// Instances are created already; print them before waiting, so that they can be
+	// found even if applying the security group fails.
+	sendResult(response, out, "RunInstance", nil)
+
+	opts := anchnet.DefaultWaitOptions
+	opts.Timeout = specWaitTimeout
+	if err = client.WaitJob(ctx, response.JobID, &opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Error waiting for instances %v: %v\n", response.InstanceIDs, err)
+		os.Exit(1)
+	}
+	apply, err := client.ApplySecurityGroup(ctx, &anchnet.ApplySecurityGroupRequest{SecurityGroupID: spec.SecurityGroupID, InstanceIDs: response.InstanceIDs})
+	if err == nil {
+		err = client.WaitJob(ctx, apply.JobID, &opts)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error applying security group %v to instances %v: %v\n", spec.SecurityGroupID, response.InstanceIDs, err)
+		os.Exit(1)
+	}
+}
+
+// parseDisk parses a data disk of format name:type:size, e.g. data:0:100 for a 100GB
+// performance disk named data. Type is 0 (performance) or 1 (capacity).
+func parseDisk(disk string) (anchnet.RunInstancesHardDisk, error) {
+	parts := strings.Split(disk, ":")
+	if len(parts) != 3 {
+		return anchnet.RunInstancesHardDisk{}, fmt.Errorf("Invalid disk %q, expected name:type:size", disk)
+	}
+	hd_type, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return anchnet.RunInstancesHardDisk{}, fmt.Errorf("Invalid type of disk %q: %v", disk, err)
+	}
+	size, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return anchnet.RunInstancesHardDisk{}, fmt.Errorf("Invalid size of disk %q: %v", disk, err)
+	}
+	return anchnet.RunInstancesHardDisk{Name: parts[0], Type: anchnet.HDType(hd_type), Unit: size}, nil
 }
 
 func execDescribeInstance(cmd *cobra.Command, args []string, client *anchnet.Client, out io.Writer) {
@@ -62,8 +203,8 @@ func execDescribeInstance(cmd *cobra.Command, args []string, client *anchnet.Cli
 		InstanceIDs: []string{args[0]},
 		Verbose:     1,
 	}
-	var response anchnet.DescribeInstancesResponse
-	sendResult(&response, out, "DescribeInstance", response.Code, client.SendRequest(request, &response))
+	response, err := client.DescribeInstances(context.Background(), &request)
+	sendResult(response, out, "DescribeInstance", err)
 }
 
 func execSearchInstance(cmd *cobra.Command, args []string, client *anchnet.Client, out io.Writer) {
@@ -82,8 +223,8 @@ func execSearchInstance(cmd *cobra.Command, args []string, client *anchnet.Clien
 		Status:     instance_status,
 		Verbose:    1,
 	}
-	var response anchnet.DescribeInstancesResponse
-	sendResult(&response, out, "SearchInstance", response.Code, client.SendRequest(request, &response))
+	response, err := client.DescribeInstances(context.Background(), &request)
+	sendResult(response, out, "SearchInstance", err)
 }
 
 func execTerminateInstances(cmd *cobra.Command, args []string, client *anchnet.Client, out io.Writer) {
@@ -95,8 +236,8 @@ func execTerminateInstances(cmd *cobra.Command, args []string, client *anchnet.C
 	request := anchnet.TerminateInstancesRequest{
 		InstanceIDs: strings.Split(args[0], ","),
 	}
-	var response anchnet.TerminateInstancesResponse
-	sendResult(&response, out, "TerminateInstance", response.Code, client.SendRequest(request, &response))
+	response, err := client.TerminateInstances(context.Background(), &request)
+	sendResult(response, out, "TerminateInstance", err)
 }
 
 func execStartInstances(cmd *cobra.Command, args []string, client *anchnet.Client, out io.Writer) {
@@ -108,8 +249,8 @@ func execStartInstances(cmd *cobra.Command, args []string, client *anchnet.Clien
 	request := anchnet.StartInstancesRequest{
 		InstanceIDs: strings.Split(args[0], ","),
 	}
-	var response anchnet.StartInstancesResponse
-	sendResult(&response, out, "StartInstance", response.Code, client.SendRequest(request, &response))
+	response, err := client.StartInstances(context.Background(), &request)
+	sendResult(response, out, "StartInstance", err)
 }
 
 func execStopInstances(cmd *cobra.Command, args []string, client *anchnet.Client, out io.Writer) {
@@ -121,6 +262,26 @@ func execStopInstances(cmd *cobra.Command, args []string, client *anchnet.Client
 	request := anchnet.StopInstancesRequest{
 		InstanceIDs: strings.Split(args[0], ","),
 	}
-	var response anchnet.StopInstancesResponse
-	sendResult(&response, out, "StopInstance", response.Code, client.SendRequest(request, &response))
+	response, err := client.StopInstances(context.Background(), &request)
+	sendResult(response, out, "StopInstance", err)
+}
+
+func execResizeInstances(cmd *cobra.Command, args []string, client *anchnet.Client, out io.Writer) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Instance IDs required")
+		os.Exit(1)
+	}
+
+	request := anchnet.ResizeInstancesRequest{
+		InstanceIDs: strings.Split(args[0], ","),
+		Cpu:         getFlagInt(cmd, "cpu"),
+		Mem:         getFlagInt(cmd, "memory"),
+	}
+	if !getFlagBool(cmd, "restart") {
+		response, err := client.ResizeInstances(context.Background(), &request)
+		sendResult(response, out, "ResizeInstances", err)
+		return
+	}
+	response, err := client.ResizeAndRestartInstances(context.Background(), &request, nil)
+	sendResult(response, out, "ResizeInstances", err)
 }
@@ -5,6 +5,8 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -23,8 +25,8 @@ func execDescribeJob(cmd *cobra.Command, args []string, client *anchnet.Client,
 	request := anchnet.DescribeJobsRequest{
 		JobIDs: []string{args[0]},
 	}
-	var response anchnet.DescribeJobsResponse
-	sendResult(&response, out, "DescribeJob", response.Code, client.SendRequest(request, &response))
+	response, err := client.DescribeJobs(context.Background(), &request)
+	sendResult(response, out, "DescribeJob", err)
 }
 
 func execWaitJob(cmd *cobra.Command, args []string, client *anchnet.Client, out io.Writer) {
@@ -36,27 +38,26 @@ func execWaitJob(cmd *cobra.Command, args []string, client *anchnet.Client, out
 	count := getFlagInt(cmd, "count")
 	status := getFlagString(cmd, "status")
 	interval := getFlagInt(cmd, "interval")
-	exitOnFail := getFlagBool(cmd, "exit_on_fail")
-
-	for i := 0; i < count; i++ {
-		request := anchnet.DescribeJobsRequest{
-			JobIDs: []string{args[0]},
-		}
-		var response anchnet.DescribeJobsResponse
-		err := client.SendRequest(request, &response)
-		if err == nil && len(response.ItemSet) == 1 {
-			// Return if there is no error and status matches.
-			if string(response.ItemSet[0].Status) == status {
-				return
-			}
-			// Return if there is no error, and status is failed + user wants early return.
-			if response.ItemSet[0].Status == anchnet.JobStatusFailed && exitOnFail {
-				fmt.Fprintf(os.Stderr, "Job %v failed", args[0])
-				os.Exit(1)
-			}
-		}
-		time.Sleep(time.Duration(interval) * time.Second)
-	}
-	fmt.Fprintf(os.Stderr, "Time out waiting for job %v", args[0])
-	os.Exit(1)
+	if count <= 0 || interval <= 0 {
+		fmt.Fprintln(os.Stderr, "Count and interval must be positive")
+		os.Exit(1)
+	}
+
+	opts := &anchnet.WaitOptions{
+		Interval: time.Duration(interval) * time.Second,
+		Timeout:  time.Duration(count*interval) * time.Second,
+	}
+	err := client.WaitJobStatus(context.Background(), args[0], anchnet.JobStatus(status), opts)
+	if jobErr, ok := err.(*anchnet.JobError); ok && jobErr.Job.Status == anchnet.JobStatusFailed {
+		fmt.Fprintf(os.Stderr, "Job %v failed\n", jobErr.JobID)
+		os.Exit(1)
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		fmt.Fprintf(os.Stderr, "Time out waiting for job %v\n", args[0])
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error waiting for job %v: %v\n", args[0], err)
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,92 @@
+// Copyright 2015 anchnet-go authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	anchnet "github.com/caicloud/anchnet-go"
+	"github.com/spf13/cobra"
+)
+
+func execCreateKeyPair(cmd *cobra.Command, args []string, client *anchnet.Client, out io.Writer) {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "Keypair name required")
+		os.Exit(1)
+	}
+
+	request := anchnet.CreateKeyPairRequest{
+		KeyPairName:   args[0],
+		Mode:          anchnet.KeyPairModeSystem,
+		EncryptMethod: anchnet.KeyPairEncryptMethod(getFlagString(cmd, "encrypt-method")),
+	}
+	if path := getFlagString(cmd, "public-key-file"); path != "" {
+		key, err := ioutil.ReadFile(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading public key file %v: %v\n", path, err)
+			os.Exit(1)
+		}
+		request.Mode = anchnet.KeyPairModeUser
+		request.EncryptMethod = ""
+		request.PublicKey = strings.TrimSpace(string(key))
+	}
+	response, err := client.CreateKeyPair(context.Background(), &request)
+	sendResult(response, out, "CreateKeyPair", err)
+}
+
+func execDescribeKeyPairs(cmd *cobra.Command, args []string, client *anchnet.Client, out io.Writer) {
+	request := anchnet.DescribeKeyPairsRequest{}
+	if len(args) > 0 {
+		request.KeyPairIDs = strings.Split(args[0], ",")
+	}
+	response, err := client.DescribeKeyPairs(context.Background(), &request)
+	sendResult(response, out, "DescribeKeyPairs", err)
+}
+
+func execDeleteKeyPairs(cmd *cobra.Command, args []string, client *anchnet.Client, out io.Writer) {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "Keypair IDs required")
+		os.Exit(1)
+	}
+
+	request := anchnet.DeleteKeyPairsRequest{
+		KeyPairIDs: strings.Split(args[0], ","),
+	}
+	response, err := client.DeleteKeyPairs(context.Background(), &request)
+	sendResult(response, out, "DeleteKeyPairs", err)
+}
+
+func execAttachKeyPairs(cmd *cobra.Command, args []string, client *anchnet.Client, out io.Writer) {
+	if len(args) != 2 {
+		fmt.Fprintln(os.Stderr, "Keypair IDs and instance IDs required")
+		os.Exit(1)
+	}
+
+	request := anchnet.AttachKeyPairsRequest{
+		KeyPairIDs:  strings.Split(args[0], ","),
+		InstanceIDs: strings.Split(args[1], ","),
+	}
+	response, err := client.AttachKeyPairs(context.Background(), &request)
+	sendResult(response, out, "AttachKeyPairs", err)
+}
+
+func execDetachKeyPairs(cmd *cobra.Command, args []string, client *anchnet.Client, out io.Writer) {
+	if len(args) != 2 {
+		fmt.Fprintln(os.Stderr, "Keypair IDs and instance IDs required")
+		os.Exit(1)
+	}
+
+	request := anchnet.DetachKeyPairsRequest{
+		KeyPairIDs:  strings.Split(args[0], ","),
+		InstanceIDs: strings.Split(args[1], ","),
+	}
+	response, err := client.DetachKeyPairs(context.Background(), &request)
+	sendResult(response, out, "DetachKeyPairs", err)
+}
@@ -5,6 +5,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -37,8 +38,8 @@ func execCreateLoadBalancer(cmd *cobra.Command, args []string, client *anchnet.C
 			Eips: ips,
 		},
 	}
-	var response anchnet.CreateLoadBalancerResponse
-	sendResult(&response, out, "CreateLoadBalancer", response.Code, client.SendRequest(request, &response))
+	response, err := client.CreateLoadBalancer(context.Background(), &request)
+	sendResult(response, out, "CreateLoadBalancer", err)
 }
 
 func execDeleteLoadBalancer(cmd *cobra.Command, args []string, client *anchnet.Client, out io.Writer) {
@@ -54,8 +55,8 @@ func execDeleteLoadBalancer(cmd *cobra.Command, args []string, client *anchnet.C
 		LoadbalancerIDs: lbs,
 		EipIDs:          ips,
 	}
-	var response anchnet.DeleteLoadBalancersResponse
-	sendResult(&response, out, "DeleteLoadBalancer", response.Code, client.SendRequest(request, &response))
+	response, err := client.DeleteLoadBalancers(context.Background(), &request)
+	sendResult(response, out, "DeleteLoadBalancer", err)
 }
 
 func execSearchLoadBalancer(cmd *cobra.Command, args []string, client *anchnet.Client, out io.Writer) {
@@ -74,6 +75,6 @@ func execSearchLoadBalancer(cmd *cobra.Command, args []string, client *anchnet.C
 		Status:     lb_status,
 		Verbose:    1,
 	}
-	var response anchnet.DescribeLoadBalancersResponse
-	sendResult(&response, out, "SearchLoadBalancer", response.Code, client.SendRequest(request, &response))
+	response, err := client.DescribeLoadBalancers(context.Background(), &request)
+	sendResult(response, out, "SearchLoadBalancer", err)
 }
@@ -17,10 +17,14 @@ func main() {
 		Use:   "anchnet",
 		Short: "anchnet is the command line interface for anchnet",
 	}
-	var config_path, project, zone string
+	var config_path, profile, project, zone, record_dir string
+	var debug bool
 	cmds.PersistentFlags().StringVarP(&config_path, "config-path", "", "", "configuration path for anchnet")
+	cmds.PersistentFlags().StringVarP(&profile, "profile", "", "", "profile in configuration file to use. Default to the default profile of the file.")
 	cmds.PersistentFlags().StringVarP(&project, "project", "", "", "anchnet sub account id")
 	cmds.PersistentFlags().StringVarP(&zone, "zone", "", "", "anchnet zone. ac1 for mainland China, ac2 for Asia-Pacific. Default to ac1.")
+	cmds.PersistentFlags().StringVarP(&record_dir, "record-dir", "", "", "directory to record requests and responses in, with secrets redacted, e.g. for test fixtures")
+	cmds.PersistentFlags().BoolVarP(&debug, "debug", "", false, "log requests and responses to stderr, with secrets redacted")
 
 	addInstancesCLI(cmds, os.Stdout)
 	addEipsCLI(cmds, os.Stdout)
@@ -31,6 +35,7 @@ func main() {
 	addUserProjectCLI(cmds, os.Stdout)
 	addVolumeCLI(cmds, os.Stdout)
 	addImageCLI(cmds, os.Stdout)
+	addKeyPairCLI(cmds, os.Stdout)
 
 	cmds.Execute()
 }
@@ -40,20 +45,33 @@ func addInstancesCLI(cmds *cobra.Command, out io.Writer) {
 	cmdRunInstance := &cobra.Command{
 		Use:   "runinstance name",
 		Short: "Create an instance",
-		Long:  "Create an instance with flag parameters. Output error or instance/eip IDs",
+		Long:  "Create an instance with flag parameters, or instances declared in a YAML or JSON spec file with -f. Output error or instance/volume/eip IDs",
 		Run: func(cmd *cobra.Command, args []string) {
 			execRunInstance(cmd, args, getAnchnetClient(cmd), out)
 		},
 	}
 	var cpu, memory, amount, bandwidth int
-	var passwd, image_id, ip_group string
+	var passwd, keypair, image_id, ip_group, user_data_file string
+	var disks, disk_ids, vxnet_name, vxnet_ids, eip_id string
+	var no_public_ip bool
+	var filename string
 	cmdRunInstance.Flags().IntVarP(&cpu, "cpu", "c", 1, "Number of cpu cores")
 	cmdRunInstance.Flags().IntVarP(&amount, "amount", "a", 1, "Number of instances to run")
 	cmdRunInstance.Flags().IntVarP(&memory, "memory", "m", 1024, "Number of memory in MB")
 	cmdRunInstance.Flags().IntVarP(&bandwidth, "bandwidth", "b", 1, "Public network bandwidth, in MB/s")
-	cmdRunInstance.Flags().StringVarP(&passwd, "passwd", "p", "caicloud2015ABC", "Login password for new instance")
+	cmdRunInstance.Flags().StringVarP(&passwd, "passwd", "p", "", "Login password for new instance; either passwd or keypair is required")
+	cmdRunInstance.Flags().StringVarP(&keypair, "keypair", "k", "", "ID of the keypair to login new instance with, instead of password")
 	cmdRunInstance.Flags().StringVarP(&image_id, "image-id", "i", "trustysrvx64c", "Image ID used to create new instance")
 	cmdRunInstance.Flags().StringVarP(&ip_group, "ip-group", "g", "eipg-00000000", "IP group of the newly created eip")
+	cmdRunInstance.Flags().StringVarP(&disks, "disks", "d", "",
+		"Comma separated list of data disks to create, each in the format name:type:size, e.g. data:0:100. Type is 0 for performance, 1 for capacity; size is in GB")
+	cmdRunInstance.Flags().StringVarP(&disk_ids, "disk-ids", "", "", "Comma separated list of existing volumes to attach to new instance")
+	cmdRunInstance.Flags().StringVarP(&vxnet_name, "vxnet-name", "", "", "Name of a private vxnet to create and join")
+	cmdRunInstance.Flags().StringVarP(&vxnet_ids, "vxnet-ids", "", "", "Comma separated list of existing vxnets to join")
+	cmdRunInstance.Flags().StringVarP(&eip_id, "eip-id", "e", "", "Existing eip to use instead of creating one; bandwidth and ip-group are ignored")
+	cmdRunInstance.Flags().BoolVarP(&no_public_ip, "no-public-ip", "", false, "Create instance without public network and eip")
+	cmdRunInstance.Flags().StringVarP(&filename, "filename", "f", "", "YAML or JSON spec of instances to create, see anchnet.RunInstancesSpec; can't be used with instance name or other flags")
+	cmdRunInstance.Flags().StringVarP(&user_data_file, "user-data-file", "u", "", "File passed to new instance as user data, e.g. a cloud-init script")
 
 	cmdDescribeInstance := &cobra.Command{
 		Use:   "describeinstance id",
@@ -98,6 +116,20 @@ func addInstancesCLI(cmds *cobra.Command, out io.Writer) {
 		},
 	}
 
+	cmdResizeInstances := &cobra.Command{
+		Use:   "resizeinstances ids",
+		Short: "Resize cpu and memory of a comma separated list of instances",
+		Long:  "Resize cpu and memory of a comma separated list of instances. Running instances are stopped, resized and started again, unless --restart=false, in which case instances must be stopped already",
+		Run: func(cmd *cobra.Command, args []string) {
+			execResizeInstances(cmd, args, getAnchnetClient(cmd), out)
+		},
+	}
+	var resize_cpu, resize_memory int
+	var restart bool
+	cmdResizeInstances.Flags().IntVarP(&resize_cpu, "cpu", "c", 0, "Number of cpu cores, 0 to keep current value")
+	cmdResizeInstances.Flags().IntVarP(&resize_memory, "memory", "m", 0, "Number of memory in MB, 0 to keep current value")
+	cmdResizeInstances.Flags().BoolVarP(&restart, "restart", "r", true, "Stop running instances before resizing, and start them again after")
+
 	// Add all sub-commands.
 	cmds.AddCommand(cmdRunInstance)
 	cmds.AddCommand(cmdDescribeInstance)
@@ -105,6 +137,7 @@ func addInstancesCLI(cmds *cobra.Command, out io.Writer) {
 	cmds.AddCommand(cmdTerminateInstances)
 	cmds.AddCommand(cmdStartInstances)
 	cmds.AddCommand(cmdStopInstances)
+	cmds.AddCommand(cmdResizeInstances)
 }
 
 // addEipsCLI adds EIP commands.
@@ -333,6 +366,7 @@ func addJobCLI(cmds *cobra.Command, out io.Writer) {
 	cmdWaitJob.Flags().IntVarP(&count, "count", "c", 20, "Number of retries")
 	cmdWaitJob.Flags().IntVarP(&interval, "interval", "i", 3, "Retry interval, in second")
 	cmdWaitJob.Flags().BoolVarP(&exitOnFail, "exit_on_fail", "r", true, "Exit early if job status is Failed")
+	cmdWaitJob.Flags().MarkDeprecated("exit_on_fail", "failed jobs never recover, waitjob always exits on failure")
 	cmdWaitJob.Flags().StringVarP(&status, "status", "s", string(anchnet.JobStatusSuccessful), "Retry interval, in second")
 
 	// Add all sub-commands.
@@ -480,3 +514,57 @@ func addImageCLI(cmds *cobra.Command, out io.Writer) {
 	cmds.AddCommand(cmdRevokeImageFromUsers)
 	cmds.AddCommand(cmdDescribeImageUsers)
 }
+
+// addKeyPairCLI adds keypair commands.
+func addKeyPairCLI(cmds *cobra.Command, out io.Writer) {
+	cmdCreateKeyPair := &cobra.Command{
+		Use:   "createkeypair name",
+		Short: "Create a keypair",
+		Long:  "Create a keypair from a public key file, or generated by anchnet if no file is given. Output error or keypair ID, and private key of generated keypair",
+		Run: func(cmd *cobra.Command, args []string) {
+			execCreateKeyPair(cmd, args, getAnchnetClient(cmd), out)
+		},
+	}
+	var public_key_file, encrypt_method string
+	cmdCreateKeyPair.Flags().StringVarP(&public_key_file, "public-key-file", "f", "", "Public key file of the keypair, e.g. ~/.ssh/id_rsa.pub")
+	cmdCreateKeyPair.Flags().StringVarP(&encrypt_method, "encrypt-method", "e", "ssh-rsa", "Encrypt method of keypair generated by anchnet: ssh-rsa, ssh-dss")
+
+	cmdDescribeKeyPairs := &cobra.Command{
+		Use:   "describekeypairs ids",
+		Short: "Describe a comma separated list of keypairs, or all keypairs if no id is given",
+		Run: func(cmd *cobra.Command, args []string) {
+			execDescribeKeyPairs(cmd, args, getAnchnetClient(cmd), out)
+		},
+	}
+
+	cmdDeleteKeyPairs := &cobra.Command{
+		Use:   "deletekeypairs ids",
+		Short: "Delete a comma separated list of keypairs",
+		Run: func(cmd *cobra.Command, args []string) {
+			execDeleteKeyPairs(cmd, args, getAnchnetClient(cmd), out)
+		},
+	}
+
+	cmdAttachKeyPairs := &cobra.Command{
+		Use:   "attachkeypairs keypair_ids instance_ids",
+		Short: "Attach a comma separated list of keypairs to a comma separated list of instances",
+		Run: func(cmd *cobra.Command, args []string) {
+			execAttachKeyPairs(cmd, args, getAnchnetClient(cmd), out)
+		},
+	}
+
+	cmdDetachKeyPairs := &cobra.Command{
+		Use:   "detachkeypairs keypair_ids instance_ids",
+		Short: "Detach a comma separated list of keypairs from a comma separated list of instances",
+		Run: func(cmd *cobra.Command, args []string) {
+			execDetachKeyPairs(cmd, args, getAnchnetClient(cmd), out)
+		},
+	}
+
+	// Add all sub-commands.
+	cmds.AddCommand(cmdCreateKeyPair)
+	cmds.AddCommand(cmdDescribeKeyPairs)
+	cmds.AddCommand(cmdDeleteKeyPairs)
+	cmds.AddCommand(cmdAttachKeyPairs)
+	cmds.AddCommand(cmdDetachKeyPairs)
+}
@@ -5,6 +5,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -50,8 +51,8 @@ func execCreateSecurityGroup(cmd *cobra.Command, args []string, client *anchnet.
 		SecurityGroupName:  args[0],
 		SecurityGroupRules: rules,
 	}
-	var response anchnet.CreateSecurityGroupResponse
-	sendResult(&response, out, "CreateSecurityGroup", response.Code, client.SendRequest(request, &response))
+	response, err := client.CreateSecurityGroup(context.Background(), &request)
+	sendResult(response, out, "CreateSecurityGroup", err)
 }
 
 func execDescribeSecurityGroup(cmd *cobra.Command, args []string, client *anchnet.Client, out io.Writer) {
@@ -64,8 +65,8 @@ func execDescribeSecurityGroup(cmd *cobra.Command, args []string, client *anchne
 		SecurityGroupIDs: []string{args[0]},
 		Verbose:          1,
 	}
-	var response anchnet.DescribeSecurityGroupsResponse
-	sendResult(&response, out, "DescribeSecurityGroup", response.Code, client.SendRequest(request, &response))
+	response, err := client.DescribeSecurityGroups(context.Background(), &request)
+	sendResult(response, out, "DescribeSecurityGroup", err)
 }
 
 func execSearchSecurityGroup(cmd *cobra.Command, args []string, client *anchnet.Client, out io.Writer) {
@@ -78,8 +79,8 @@ func execSearchSecurityGroup(cmd *cobra.Command, args []string, client *anchnet.
 		SearchWord: args[0],
 		Verbose:    1,
 	}
-	var response anchnet.DescribeSecurityGroupsResponse
-	sendResult(&response, out, "SearchSecurityGroup", response.Code, client.SendRequest(request, &response))
+	response, err := client.DescribeSecurityGroups(context.Background(), &request)
+	sendResult(response, out, "SearchSecurityGroup", err)
 }
 
 func execAddSecurityGroupRule(cmd *cobra.Command, args []string, client *anchnet.Client, out io.Writer) {
@@ -111,8 +112,8 @@ func execAddSecurityGroupRule(cmd *cobra.Command, args []string, client *anchnet
 			},
 		},
 	}
-	var response anchnet.AddSecurityGroupRulesResponse
-	sendResult(&response, out, "AddSecurityGroup", response.Code, client.SendRequest(request, &response))
+	response, err := client.AddSecurityGroupRules(context.Background(), &request)
+	sendResult(response, out, "AddSecurityGroup", err)
 }
 
 func execApplySecurityGroup(cmd *cobra.Command, args []string, client *anchnet.Client, out io.Writer) {
@@ -125,8 +126,8 @@ func execApplySecurityGroup(cmd *cobra.Command, args []string, client *anchnet.C
 		SecurityGroupID: args[0],
 		InstanceIDs:     strings.Split(args[1], ","),
 	}
-	var response anchnet.ApplySecurityGroupResponse
-	sendResult(&response, out, "ApplySecurityGroup", response.Code, client.SendRequest(request, &response))
+	response, err := client.ApplySecurityGroup(context.Background(), &request)
+	sendResult(response, out, "ApplySecurityGroup", err)
 }
 
 func execDeleteSecurityGroups(cmd *cobra.Command, args []string, client *anchnet.Client, out io.Writer) {
@@ -138,6 +139,6 @@ func execDeleteSecurityGroups(cmd *cobra.Command, args []string, client *anchnet
 	request := anchnet.DeleteSecurityGroupsRequest{
 		SecurityGroupIDs: strings.Split(args[0], ","),
 	}
-	var response anchnet.DeleteSecurityGroupsResponse
-	sendResult(&response, out, "DeleteSecurityGroups", response.Code, client.SendRequest(request, &response))
+	response, err := client.DeleteSecurityGroups(context.Background(), &request)
+	sendResult(response, out, "DeleteSecurityGroups", err)
 }
@@ -5,6 +5,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -36,8 +37,8 @@ func execCreateUserProject(cmd *cobra.Command, args []string, client *anchnet.Cl
 		Mobile:      mobile,
 		LoginPasswd: passwd,
 	}
-	var response anchnet.CreateUserProjectResponse
-	sendResult(&response, out, "CreateUserProject", response.Code, client.SendRequest(request, &response))
+	response, err := client.CreateUserProject(context.Background(), &request)
+	sendResult(response, out, "CreateUserProject", err)
 }
 
 func execDescribeProjects(cmd *cobra.Command, args []string, client *anchnet.Client, out io.Writer) {
@@ -50,8 +51,8 @@ func execDescribeProjects(cmd *cobra.Command, args []string, client *anchnet.Cli
 		Projects: args[0],
 	}
 
-	var response anchnet.DescribeProjectsResponse
-	sendResult(&response, out, "DescribeProjects", response.Code, client.SendRequest(request, &response))
+	response, err := client.DescribeProjects(context.Background(), &request)
+	sendResult(response, out, "DescribeProjects", err)
 }
 
 func execTransfer(cmd *cobra.Command, args []string, client *anchnet.Client, out io.Writer) {
@@ -65,7 +66,7 @@ func execTransfer(cmd *cobra.Command, args []string, client *anchnet.Client, out
 	// userID is a integer value.
 	userID, err := strconv.Atoi(args[0])
 	if err != nil {
-		fmt.Fprintln(os.Stderr, "unable to convert userID to int: %v", err)
+		fmt.Fprintf(os.Stderr, "unable to convert userID to int: %v\n", err)
 		os.Exit(1)
 	}
 
@@ -75,8 +76,8 @@ func execTransfer(cmd *cobra.Command, args []string, client *anchnet.Client, out
 		Why:    why,
 	}
 
-	var response anchnet.TransferResponse
-	sendResult(&response, out, "Transfer", response.Code, client.SendRequest(request, &response))
+	response, err := client.Transfer(context.Background(), &request)
+	sendResult(response, out, "Transfer", err)
 }
 
 func execSearchUserProject(cmd *cobra.Command, args []string, client *anchnet.Client, out io.Writer) {
@@ -91,8 +92,8 @@ func execSearchUserProject(cmd *cobra.Command, args []string, client *anchnet.Cl
 		SearchWord: loginID,
 	}
 
-	var response anchnet.DescribeProjectsResponse
-	sendResult(&response, out, "SearchUserProject", response.Code, client.SendRequest(request, &response))
+	response, err := client.DescribeProjects(context.Background(), &request)
+	sendResult(response, out, "SearchUserProject", err)
 }
 
 func execSearchUser(cmd *cobra.Command, args []string, client *anchnet.Client, out io.Writer) {
@@ -108,13 +109,13 @@ func execSearchUser(cmd *cobra.Command, args []string, client *anchnet.Client, o
 		Type:       "sub",
 	}
 
-	var response anchnet.DescribeUsersResponse
-	sendResult(&response, out, "DescribeUsers", response.Code, client.SendRequest(request, &response))
+	response, err := client.DescribeUsers(context.Background(), &request)
+	sendResult(response, out, "DescribeUsers", err)
 }
 
 func execGetChargeSummary(cmd *cobra.Command, args []string, client *anchnet.Client, out io.Writer) {
 	request := anchnet.GetChargeSummaryRequest{}
 
-	var response anchnet.GetChargeSummaryResponse
-	sendResult(&response, out, "GetChargeSummary", response.Code, client.SendRequest(request, &response))
+	response, err := client.GetChargeSummary(context.Background(), &request)
+	sendResult(response, out, "GetChargeSummary", err)
 }
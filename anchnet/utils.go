@@ -8,10 +8,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log"
+	"net/http"
 	"os"
 	"strconv"
 
 	anchnet "github.com/caicloud/anchnet-go"
+	"github.com/caicloud/anchnet-go/replay"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 )
@@ -25,7 +28,7 @@ func getFlagBool(cmd *cobra.Command, flag string) bool {
 	f := getFlag(cmd, flag)
 	result, err := strconv.ParseBool(f.Value.String())
 	if err != nil {
-		fmt.Fprintln(os.Stderr, "Invalid value for a boolean flag: %s", f.Value.String())
+		fmt.Fprintf(os.Stderr, "Invalid value for a boolean flag: %s\n", f.Value.String())
 		os.Exit(1)
 	}
 	return result
@@ -38,7 +41,7 @@ func getFlagInt(cmd *cobra.Command, flag string) int {
 	// This is likely not a sufficiently friendly error message, but cobra
 	// should prevent non-integer values from reaching here.
 	if err != nil {
-		fmt.Fprintln(os.Stderr, "unable to convert flag value to int: %v", err)
+		fmt.Fprintf(os.Stderr, "unable to convert flag value to int: %v\n", err)
 		os.Exit(1)
 	}
 	return v
@@ -47,7 +50,7 @@ func getFlagInt(cmd *cobra.Command, flag string) int {
 func getFlag(cmd *cobra.Command, flag string) *pflag.Flag {
 	f := cmd.Flags().Lookup(flag)
 	if f == nil {
-		fmt.Fprintln(os.Stderr, "flag accessed but not defined for command %s: %s", cmd.Name(), flag)
+		fmt.Fprintf(os.Stderr, "flag accessed but not defined for command %s: %s\n", cmd.Name(), flag)
 		os.Exit(1)
 	}
 	return f
@@ -56,11 +59,12 @@ func getFlag(cmd *cobra.Command, flag string) *pflag.Flag {
 // getAnchnetClient returns the path to configuration file.
 func getAnchnetClient(cmd *cobra.Command) *anchnet.Client {
 	f := cmd.InheritedFlags().Lookup("config-path")
+	n := cmd.InheritedFlags().Lookup("profile")
 	p := cmd.InheritedFlags().Lookup("project")
 	z := cmd.InheritedFlags().Lookup("zone")
 
 	if f == nil {
-		fmt.Fprintln(os.Stderr, "flag accessed but not defined for command %s: config-path", cmd.Name())
+		fmt.Fprintf(os.Stderr, "flag accessed but not defined for command %s: config-path\n", cmd.Name())
 		os.Exit(1)
 	}
 	path := f.Value.String()
@@ -68,62 +72,79 @@ func getAnchnetClient(cmd *cobra.Command) *anchnet.Client {
 		path = anchnet.DefaultConfigPath()
 	}
 
+	if n == nil {
+		fmt.Fprintf(os.Stderr, "flag accessed but not defined for command %s: profile\n", cmd.Name())
+		os.Exit(1)
+	}
+
 	if p == nil {
-		fmt.Fprintln(os.Stderr, "flag accessed but not defined for command %s: project", cmd.Name())
+		fmt.Fprintf(os.Stderr, "flag accessed but not defined for command %s: project\n", cmd.Name())
 		os.Exit(1)
 	}
 
 	if z == nil {
-		fmt.Fprintln(os.Stderr, "flag accessed but not defined for command %s: zone", cmd.Name())
+		fmt.Fprintf(os.Stderr, "flag accessed but not defined for command %s: zone\n", cmd.Name())
 		os.Exit(1)
 	}
 
-	auth, err := anchnet.LoadConfig(path)
+	config, err := anchnet.LoadProfileConfig(path)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, "error loading auth config: %v", err)
+		fmt.Fprintf(os.Stderr, "error loading auth config: %v\n", err)
+		os.Exit(1)
+	}
+	profile, err := config.Profile(n.Value.String())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading auth config: %v\n", err)
 		os.Exit(1)
 	}
 
-	// we only set ProjectId if --project is set because
-	// projectid can also be set in config file itself already
+	// we only set ProjectId and zone if --project and --zone are set because
+	// they can also be set in config file itself already
 	project := p.Value.String()
 	if project != "" {
-		auth.ProjectId = project
+		profile.ProjectId = project
 	}
-
-	client, err := anchnet.NewClient(anchnet.DefaultEndpoint, auth)
 	zone := z.Value.String()
 	if zone != "" {
-		client.SetZone(zone)
+		profile.Zone = zone
 	}
+
+	client, err := anchnet.NewClientFromProfile(profile)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, "error creating client: %v", err)
+		fmt.Fprintf(os.Stderr, "error creating client: %v\n", err)
 		os.Exit(1)
 	}
 
+	if r := cmd.InheritedFlags().Lookup("record-dir"); r != nil && r.Value.String() != "" {
+		client.HTTPClient = &http.Client{Transport: &replay.Transport{Dir: r.Value.String(), Mode: replay.Record}}
+	}
+	if d := cmd.InheritedFlags().Lookup("debug"); d != nil && d.Value.String() == "true" {
+		client.Logger = log.New(os.Stderr, "", log.LstdFlags)
+	}
+
 	return client
 }
 
 // sendResult sends response to out. cmdName is the command name that we just sent to
-// anchnet; code is response.Code and err is from clinet.SendRequest(). The last three
-// parameters are needed since we use interface{} type for response.
-func sendResult(response interface{}, out io.Writer, cmdName string, code int, err error) {
-	// If anchnet error code == 0 but err != nil, we encountered unexpected exceptions.
-	if err != nil && code == 0 {
+// anchnet; err is from the client call, e.g. client.RunInstances().
+func sendResult(response interface{}, out io.Writer, cmdName string, err error) {
+	// If err is not an anchnet error, we encountered unexpected exceptions.
+	_, isAPIError := anchnet.IsAPIError(err)
+	if err != nil && !isAPIError {
 		fmt.Fprintf(os.Stderr, "Unexpected error running command %v: %v\n", cmdName, err)
 		os.Exit(1)
 	}
 
-	output, err := json.Marshal(response)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Unexpected error marshaling output for command %v: %v\n", cmdName, err)
+	output, marshalErr := json.Marshal(response)
+	if marshalErr != nil {
+		fmt.Fprintf(os.Stderr, "Unexpected error marshaling output for command %v: %v\n", cmdName, marshalErr)
 		os.Exit(1)
 	}
 
 	// If we did receive a response, send it to client, regardless of error code from anchnet.
-	// However, we exit with non-zero if code != 0.
+	// However, we exit with non-zero if anchnet returns an error.
 	fmt.Fprintf(out, "%v", string(output))
-	if code != 0 {
+	if err != nil {
 		os.Exit(1)
 	}
 }
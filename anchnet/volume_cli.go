@@ -5,6 +5,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -23,8 +24,8 @@ func execDescribeVolumes(cmd *cobra.Command, args []string, client *anchnet.Clie
 	request := anchnet.DescribeVolumesRequest{
 		VolumeIDs: strings.Split(args[0], ","),
 	}
-	var response anchnet.DescribeVolumesResponse
-	sendResult(&response, out, "DescribeVolumes", response.Code, client.SendRequest(request, &response))
+	response, err := client.DescribeVolumes(context.Background(), &request)
+	sendResult(response, out, "DescribeVolumes", err)
 }
 
 func execDetachVolumes(cmd *cobra.Command, args []string, client *anchnet.Client, out io.Writer) {
@@ -36,8 +37,8 @@ func execDetachVolumes(cmd *cobra.Command, args []string, client *anchnet.Client
 	request := anchnet.DetachVolumesRequest{
 		VolumeIDs: strings.Split(args[0], ","),
 	}
-	var response anchnet.DetachVolumesResponse
-	sendResult(&response, out, "DetachVolumes", response.Code, client.SendRequest(request, &response))
+	response, err := client.DetachVolumes(context.Background(), &request)
+	sendResult(response, out, "DetachVolumes", err)
 }
 
 func execDeleteVolumes(cmd *cobra.Command, args []string, client *anchnet.Client, out io.Writer) {
@@ -49,6 +50,6 @@ func execDeleteVolumes(cmd *cobra.Command, args []string, client *anchnet.Client
 	request := anchnet.DeleteVolumesRequest{
 		VolumeIDs: strings.Split(args[0], ","),
 	}
-	var response anchnet.DeleteVolumesResponse
-	sendResult(&response, out, "DeleteVolumes", response.Code, client.SendRequest(request, &response))
+	response, err := client.DeleteVolumes(context.Background(), &request)
+	sendResult(response, out, "DeleteVolumes", err)
 }
@@ -5,6 +5,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -25,8 +26,8 @@ func execCreateVxnet(cmd *cobra.Command, args []string, client *anchnet.Client,
 		VxnetType: anchnet.VxnetTypePriv,
 		Count:     1,
 	}
-	var response anchnet.CreateVxnetsResponse
-	sendResult(&response, out, "CreateVxnet", response.Code, client.SendRequest(request, &response))
+	response, err := client.CreateVxnets(context.Background(), &request)
+	sendResult(response, out, "CreateVxnet", err)
 }
 
 func execDescribeVxnets(cmd *cobra.Command, args []string, client *anchnet.Client, out io.Writer) {
@@ -38,8 +39,8 @@ func execDescribeVxnets(cmd *cobra.Command, args []string, client *anchnet.Clien
 	request := anchnet.DescribeVxnetsRequest{
 		VxnetIDs: strings.Split(args[0], ","),
 	}
-	var response anchnet.DescribeVxnetsResponse
-	sendResult(&response, out, "DescribeVxnet", response.Code, client.SendRequest(request, &response))
+	response, err := client.DescribeVxnets(context.Background(), &request)
+	sendResult(response, out, "DescribeVxnet", err)
 }
 
 func execSearchVxnets(cmd *cobra.Command, args []string, client *anchnet.Client, out io.Writer) {
@@ -52,8 +53,8 @@ func execSearchVxnets(cmd *cobra.Command, args []string, client *anchnet.Client,
 		SearchWord: args[0],
 		Verbose:    1,
 	}
-	var response anchnet.DescribeVxnetsResponse
-	sendResult(&response, out, "SearchVxnet", response.Code, client.SendRequest(request, &response))
+	response, err := client.DescribeVxnets(context.Background(), &request)
+	sendResult(response, out, "SearchVxnet", err)
 }
 
 func execJoinVxnet(cmd *cobra.Command, args []string, client *anchnet.Client, out io.Writer) {
@@ -66,8 +67,8 @@ func execJoinVxnet(cmd *cobra.Command, args []string, client *anchnet.Client, ou
 		VxnetID:     args[0],
 		InstanceIDs: strings.Split(args[1], ","),
 	}
-	var response anchnet.JoinVxnetResponse
-	sendResult(&response, out, "JobVxnet", response.Code, client.SendRequest(request, &response))
+	response, err := client.JoinVxnet(context.Background(), &request)
+	sendResult(response, out, "JobVxnet", err)
 }
 
 func execDeleteVxnets(cmd *cobra.Command, args []string, client *anchnet.Client, out io.Writer) {
@@ -79,6 +80,6 @@ func execDeleteVxnets(cmd *cobra.Command, args []string, client *anchnet.Client,
 	request := anchnet.DeleteVxnetsRequest{
 		VxnetIDs: strings.Split(args[0], ","),
 	}
-	var response anchnet.DeleteVxnetsResponse
-	sendResult(&response, out, "DeleteVxnet", response.Code, client.SendRequest(request, &response))
+	response, err := client.DeleteVxnets(context.Background(), &request)
+	sendResult(response, out, "DeleteVxnet", err)
 }
@@ -0,0 +1,219 @@
+// Copyright 2015 anchnet-go authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package anchnettest
+
+import (
+	"fmt"
+	"strings"
+
+	anchnet "github.com/caicloud/anchnet-go"
+)
+
+// Implements fake anchnet eip related APIs.
+
+// eipsIn returns eips of ids, or error if any of them doesn't exist or isn't in
+// one of statuses.
+func (s *Server) eipsIn(ids []string, statuses ...anchnet.EipStatus) ([]*anchnet.DescribeEipsItem, error) {
+	if len(ids) == 0 {
+		return nil, errorf(anchnet.ErrorCodeInvalidParameter, "no eip given")
+	}
+	var items []*anchnet.DescribeEipsItem
+	for _, id := range ids {
+		item, ok := s.eips[id]
+		if !ok {
+			return nil, notFound("eip", id)
+		}
+		if !hasEipStatus(item.Status, statuses) {
+			return nil, wrongStatus(id, item.Status)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func hasEipStatus(status anchnet.EipStatus, statuses []anchnet.EipStatus) bool {
+	for _, s := range statuses {
+		if status == s {
+			return true
+		}
+	}
+	return false
+}
+
+// transitEips sets eips pending, until job of action finishes: they become 'to'
+// and change is called if it succeeds, or they get back their previous status
+// if it fails. change can be nil.
+func (s *Server) transitEips(action string, items []*anchnet.DescribeEipsItem, to anchnet.EipStatus, change func(item *anchnet.DescribeEipsItem)) string {
+	from := make(map[*anchnet.DescribeEipsItem]anchnet.EipStatus)
+	for _, item := range items {
+		from[item] = item.Status
+		s.setEipStatus(item, anchnet.EipStatusPending)
+	}
+	return s.newJob(action, func() {
+		for _, item := range items {
+			if change != nil {
+				change(item)
+			}
+			s.setEipStatus(item, to)
+		}
+	}, func() {
+		for _, item := range items {
+			s.setEipStatus(item, from[item])
+		}
+	})
+}
+
+func (s *Server) setEipStatus(item *anchnet.DescribeEipsItem, status anchnet.EipStatus) {
+	item.Status = status
+	item.StatusTime = anchnet.NewTime(s.now())
+}
+
+// createEip creates a pending eip, with an address from 10.0.0.0/8.
+func (s *Server) createEip(group anchnet.IPGroupType, bandwidth int) *anchnet.DescribeEipsItem {
+	now := anchnet.NewTime(s.now())
+	item := &anchnet.DescribeEipsItem{
+		EipID:      s.newID("eip"),
+		EipAddr:    fmt.Sprintf("10.%d.%d.%d", s.seq>>16&0xff, s.seq>>8&0xff, s.seq&0xff),
+		Bandwidth:  bandwidth,
+		Status:     anchnet.EipStatusPending,
+		CreateTime: now,
+		StatusTime: now,
+		EipGroup:   anchnet.DescribeEipsEipGroup{EipGroupID: string(group)},
+	}
+	s.eips[item.EipID] = item
+	return item
+}
+
+func init() {
+	handlers["DescribeEips"] = func(s *Server, body []byte) (interface{}, error) {
+		var request anchnet.DescribeEipsRequest
+		if err := decode(body, &request); err != nil {
+			return nil, err
+		}
+		var ids []string
+		for _, id := range selectIDs(request.EipIDs, func(id string) bool { return s.eips[id] != nil }, func() []string { return sortedKeys(s.eips) }) {
+			item := s.eips[id]
+			if len(request.Status) > 0 && !hasEipStatus(item.Status, request.Status) {
+				continue
+			}
+			if !strings.Contains(item.EipName, request.SearchWord) && !strings.Contains(item.EipAddr, request.SearchWord) {
+				continue
+			}
+			ids = append(ids, id)
+		}
+		response := &anchnet.DescribeEipsResponse{TotalCount: len(ids)}
+		for _, id := range page(ids, request.Offset, request.Limit) {
+			response.ItemSet = append(response.ItemSet, *s.eips[id])
+		}
+		return response, nil
+	}
+
+	handlers["AllocateEips"] = func(s *Server, body []byte) (interface{}, error) {
+		var request anchnet.AllocateEipsRequest
+		if err := decode(body, &request); err != nil {
+			return nil, err
+		}
+		ip := request.Product.IP
+		if ip.Bandwidth <= 0 {
+			return nil, errorf(anchnet.ErrorCodeInvalidParameter, "bandwidth must be positive, got %v", ip.Bandwidth)
+		}
+		amount := ip.Amount
+		if amount <= 0 {
+			amount = 1
+		}
+		response := &anchnet.AllocateEipsResponse{}
+		var items []*anchnet.DescribeEipsItem
+		for i := 0; i < amount; i++ {
+			item := s.createEip(ip.IPGroup, ip.Bandwidth)
+			items = append(items, item)
+			response.EipIDs = append(response.EipIDs, item.EipID)
+		}
+		response.JobID = s.newJob("AllocateEips", func() {
+			for _, item := range items {
+				s.setEipStatus(item, anchnet.EipStatusAvailable)
+			}
+		}, func() {
+			for _, item := range items {
+				s.setEipStatus(item, anchnet.EipStatusSuspended)
+			}
+		})
+		return response, nil
+	}
+
+	handlers["ReleaseEips"] = func(s *Server, body []byte) (interface{}, error) {
+		var request anchnet.ReleaseEipsRequest
+		if err := decode(body, &request); err != nil {
+			return nil, err
+		}
+		items, err := s.eipsIn(request.EipIDs, anchnet.EipStatusAvailable, anchnet.EipStatusSuspended)
+		if err != nil {
+			return nil, err
+		}
+		jobID := s.transitEips("ReleaseEips", items, anchnet.EipStatusPending, func(item *anchnet.DescribeEipsItem) {
+			delete(s.eips, item.EipID)
+		})
+		return &anchnet.ReleaseEipsResponse{JobID: jobID}, nil
+	}
+
+	handlers["AssociateEip"] = func(s *Server, body []byte) (interface{}, error) {
+		var request anchnet.AssociateEipRequest
+		if err := decode(body, &request); err != nil {
+			return nil, err
+		}
+		instances, err := s.instancesIn([]string{request.InstanceID}, anchnet.InstanceStatusRunning, anchnet.InstanceStatusStopped)
+		if err != nil {
+			return nil, err
+		}
+		for _, eip := range s.eips {
+			if eip.Resource.ResourceID == request.InstanceID {
+				return nil, errorf(anchnet.ErrorCodeInvalidParameter, "instance %v already has eip %v", request.InstanceID, eip.EipID)
+			}
+		}
+		items, err := s.eipsIn([]string{request.EipID}, anchnet.EipStatusAvailable)
+		if err != nil {
+			return nil, err
+		}
+		jobID := s.transitEips("AssociateEip", items, anchnet.EipStatusAssociated, func(item *anchnet.DescribeEipsItem) {
+			item.Resource = anchnet.DescribeEipsResource{ResourceID: instances[0].InstanceID, ResourceName: instances[0].InstanceName, ResourceType: "instance"}
+		})
+		return &anchnet.AssociateEipResponse{JobID: jobID}, nil
+	}
+
+	handlers["DissociateEips"] = func(s *Server, body []byte) (interface{}, error) {
+		var request anchnet.DissociateEipsRequest
+		if err := decode(body, &request); err != nil {
+			return nil, err
+		}
+		items, err := s.eipsIn(request.EipIDs, anchnet.EipStatusAssociated)
+		if err != nil {
+			return nil, err
+		}
+		jobID := s.transitEips("DissociateEips", items, anchnet.EipStatusAvailable, func(item *anchnet.DescribeEipsItem) {
+			item.Resource = anchnet.DescribeEipsResource{}
+			item.Loadbalancer = anchnet.DescribeEipsLoadbalancer{}
+		})
+		return &anchnet.DissociateEipsResponse{JobID: jobID}, nil
+	}
+
+	handlers["ChangeEipsBandwidth"] = func(s *Server, body []byte) (interface{}, error) {
+		var request anchnet.ChangeEipsBandwidthRequest
+		if err := decode(body, &request); err != nil {
+			return nil, err
+		}
+		if request.Bandwidth <= 0 {
+			return nil, errorf(anchnet.ErrorCodeInvalidParameter, "bandwidth must be positive, got %v", request.Bandwidth)
+		}
+		items, err := s.eipsIn(request.EipIDs, anchnet.EipStatusAvailable, anchnet.EipStatusAssociated)
+		if err != nil {
+			return nil, err
+		}
+		jobID := s.newJob("ChangeEipsBandwidth", func() {
+			for _, item := range items {
+				item.Bandwidth = request.Bandwidth
+			}
+		}, nil)
+		return &anchnet.ChangeEipsBandwidthResponse{JobID: jobID}, nil
+	}
+}
@@ -0,0 +1,421 @@
+// Copyright 2015 anchnet-go authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package anchnettest
+
+import (
+	"strconv"
+	"strings"
+
+	anchnet "github.com/caicloud/anchnet-go"
+)
+
+// Implements fake anchnet instance related APIs.
+
+// instancesIn returns instances of ids, or error if any of them doesn't exist or
+// isn't in one of statuses.
+func (s *Server) instancesIn(ids []string, statuses ...anchnet.InstanceStatus) ([]*anchnet.DescribeInstancesItem, error) {
+	if len(ids) == 0 {
+		return nil, errorf(anchnet.ErrorCodeInvalidParameter, "no instance given")
+	}
+	var items []*anchnet.DescribeInstancesItem
+	for _, id := range ids {
+		item, ok := s.instances[id]
+		if !ok {
+			return nil, notFound("instance", id)
+		}
+		if !hasInstanceStatus(item.Status, statuses) {
+			return nil, wrongStatus(id, item.Status)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func hasInstanceStatus(status anchnet.InstanceStatus, statuses []anchnet.InstanceStatus) bool {
+	for _, s := range statuses {
+		if status == s {
+			return true
+		}
+	}
+	return false
+}
+
+// transitInstances sets instances pending, until job of action finishes: they
+// become 'to' if it succeeds, or their previous status if it fails.
+func (s *Server) transitInstances(action string, items []*anchnet.DescribeInstancesItem, to anchnet.InstanceStatus) string {
+	from := make(map[*anchnet.DescribeInstancesItem]anchnet.InstanceStatus)
+	for _, item := range items {
+		from[item] = item.Status
+		s.setInstanceStatus(item, anchnet.InstanceStatusPending)
+	}
+	return s.newJob(action, func() {
+		for _, item := range items {
+			s.setInstanceStatus(item, to)
+		}
+	}, func() {
+		for _, item := range items {
+			s.setInstanceStatus(item, from[item])
+		}
+	})
+}
+
+func (s *Server) setInstanceStatus(item *anchnet.DescribeInstancesItem, status anchnet.InstanceStatus) {
+	item.Status = status
+	item.StatusTime = anchnet.NewTime(s.now())
+}
+
+// describeInstance returns a copy of an instance, along with the volumes, eip and
+// vxnets attached to it.
+func (s *Server) describeInstance(id string) anchnet.DescribeInstancesItem {
+	item := *s.instances[id]
+	item.Volumes, item.VolumeIDs, item.Vxnets, item.KeyPairIDs = nil, nil, nil, nil
+	for _, volumeID := range sortedKeys(s.volumes) {
+		volume := s.volumes[volumeID]
+		if volume.Instance.InstanceID == id {
+			item.Volumes = append(item.Volumes, anchnet.DescribeInstancesVolume{
+				Size:       volume.Size,
+				VolumeID:   volume.VolumeID,
+				VolumeName: volume.VolumeName,
+				VolumeType: volume.VolumeType,
+			})
+			item.VolumeIDs = append(item.VolumeIDs, volumeID)
+		}
+	}
+	for _, eip := range s.eips {
+		if eip.Resource.ResourceID == id {
+			item.EIP = anchnet.DescribeInstancesEIP{EipID: eip.EipID, EipName: eip.EipName, EipAddr: eip.EipAddr}
+		}
+	}
+	for _, vxnetID := range sortedKeys(s.vxnets) {
+		vxnet := s.vxnets[vxnetID]
+		for _, instance := range vxnet.Instances {
+			if instance.InstanceID == id {
+				item.Vxnets = append(item.Vxnets, anchnet.DescribeInstancesVxnet{
+					VxnetID:   vxnet.VxnetID,
+					VxnetName: vxnet.VxnetName,
+					VxnetType: vxnet.VxnetType,
+					Systype:   vxnet.Systype,
+				})
+			}
+		}
+	}
+	for _, keypairID := range sortedKeys(s.keypairs) {
+		for _, instanceID := range s.keypairs[keypairID].InstanceIDs {
+			if instanceID == id {
+				item.KeyPairIDs = append(item.KeyPairIDs, keypairID)
+			}
+		}
+	}
+	return item
+}
+
+// detachInstance detaches all volumes, eip, vxnets and keypairs from an instance.
+func (s *Server) detachInstance(id string) {
+	for _, volume := range s.volumes {
+		if volume.Instance.InstanceID == id {
+			volume.Instance = anchnet.DescribeVolumesInstance{}
+			s.setVolumeStatus(volume, anchnet.VolumeStatusAvailable)
+		}
+	}
+	for _, eip := range s.eips {
+		if eip.Resource.ResourceID == id {
+			eip.Resource = anchnet.DescribeEipsResource{}
+			s.setEipStatus(eip, anchnet.EipStatusAvailable)
+		}
+	}
+	for _, vxnet := range s.vxnets {
+		s.leaveVxnet(vxnet, id)
+	}
+	for _, keypair := range s.keypairs {
+		s.detachKeyPair(keypair, id)
+	}
+}
+
+func init() {
+	handlers["DescribeInstances"] = func(s *Server, body []byte) (interface{}, error) {
+		var request anchnet.DescribeInstancesRequest
+		if err := decode(body, &request); err != nil {
+			return nil, err
+		}
+		var ids []string
+		for _, id := range selectIDs(request.InstanceIDs, func(id string) bool { return s.instances[id] != nil }, func() []string { return sortedKeys(s.instances) }) {
+			item := s.instances[id]
+			if len(request.Status) > 0 && !hasInstanceStatus(item.Status, request.Status) {
+				continue
+			}
+			if !strings.Contains(item.InstanceName, request.SearchWord) {
+				continue
+			}
+			ids = append(ids, id)
+		}
+		response := &anchnet.DescribeInstancesResponse{TotalCount: len(ids)}
+		for _, id := range page(ids, request.Offset, request.Limit) {
+			response.ItemSet = append(response.ItemSet, s.describeInstance(id))
+		}
+		return response, nil
+	}
+
+	handlers["RunInstances"] = func(s *Server, body []byte) (interface{}, error) {
+		var request anchnet.RunInstancesRequest
+		if err := decode(body, &request); err != nil {
+			return nil, err
+		}
+		cloud := request.Product.Cloud
+		amount := cloud.Amount
+		if amount <= 0 {
+			amount = 1
+		}
+
+		// Check existing resources before creating anything.
+		var existingVolumes []*anchnet.DescribeVolumesItem
+		for _, hd := range cloud.HD {
+			if len(hd.HdIDs) == 0 {
+				continue
+			}
+			volumes, err := s.volumesIn(hd.HdIDs, anchnet.VolumeStatusAvailable)
+			if err != nil {
+				return nil, err
+			}
+			existingVolumes = append(existingVolumes, volumes...)
+		}
+		var existingEip *anchnet.DescribeEipsItem
+		if cloud.IP.EipID != "" {
+			eips, err := s.eipsIn([]string{cloud.IP.EipID}, anchnet.EipStatusAvailable)
+			if err != nil {
+				return nil, err
+			}
+			existingEip = eips[0]
+		}
+		var vxnets []*anchnet.DescribeVxnetsItem
+		for _, net := range cloud.Net1 {
+			for _, id := range net.VxnetIDs {
+				vxnet, ok := s.vxnets[id]
+				if !ok {
+					return nil, notFound("vxnet", id)
+				}
+				vxnets = append(vxnets, vxnet)
+			}
+		}
+		var keypair *anchnet.DescribeKeyPairsItem
+		if cloud.VM.LoginMode == anchnet.LoginModeKeypair {
+			var err error
+			if keypair, err = s.keyPair(cloud.VM.KeyPairID); err != nil {
+				return nil, err
+			}
+		}
+		if (len(existingVolumes) > 0 || existingEip != nil) && amount > 1 {
+			return nil, errorf(anchnet.ErrorCodeInvalidParameter, "existing volumes and eips can only be used by one instance")
+		}
+
+		response := &anchnet.RunInstancesResponse{}
+		var instances []*anchnet.DescribeInstancesItem
+		var volumes []*anchnet.DescribeVolumesItem
+		var eips []*anchnet.DescribeEipsItem
+		for _, net := range cloud.Net1 {
+			if net.VxnetName != "" && net.Checked {
+				vxnets = append(vxnets, s.createVxnet(net.VxnetName, anchnet.VxnetTypePriv))
+			}
+		}
+		for i := 0; i < amount; i++ {
+			now := anchnet.NewTime(s.now())
+			instance := &anchnet.DescribeInstancesItem{
+				InstanceID:    s.newID("i"),
+				InstanceName:  cloud.VM.Name,
+				VcpusCurrent:  cloud.VM.Cpu,
+				MemoryCurrent: cloud.VM.Mem,
+				Status:        anchnet.InstanceStatusPending,
+				CreateTime:    now,
+				StatusTime:    now,
+				Image:         anchnet.DescribeInstancesImage{ImageID: cloud.VM.ImageID},
+			}
+			s.instances[instance.InstanceID] = instance
+			instances = append(instances, instance)
+			response.InstanceIDs = append(response.InstanceIDs, instance.InstanceID)
+
+			for _, hd := range cloud.HD {
+				if hd.Unit > 0 {
+					volume := s.createVolume(hd.Name, anchnet.VolumeType(strconv.Itoa(int(hd.Type))), hd.Unit)
+					volume.Instance = anchnet.DescribeVolumesInstance{InstanceID: instance.InstanceID, InstanceName: instance.InstanceName}
+					volumes = append(volumes, volume)
+					response.VolumeIDs = append(response.VolumeIDs, volume.VolumeID)
+				}
+			}
+			for _, volume := range existingVolumes {
+				volume.Instance = anchnet.DescribeVolumesInstance{InstanceID: instance.InstanceID, InstanceName: instance.InstanceName}
+				s.setVolumeStatus(volume, anchnet.VolumeStatusPending)
+			}
+
+			eip := existingEip
+			if eip == nil && cloud.Net0 && cloud.IP.Bandwidth > 0 {
+				eip = s.createEip(cloud.IP.IPGroup, cloud.IP.Bandwidth)
+				eips = append(eips, eip)
+				response.EipIDs = append(response.EipIDs, eip.EipID)
+			}
+			if eip != nil {
+				eip.Resource = anchnet.DescribeEipsResource{ResourceID: instance.InstanceID, ResourceName: instance.InstanceName, ResourceType: "instance"}
+				s.setEipStatus(eip, anchnet.EipStatusPending)
+			}
+
+			for _, vxnet := range vxnets {
+				vxnet.Instances = append(vxnet.Instances, anchnet.DescribeVxnetsInstance{InstanceID: instance.InstanceID, InstanceName: instance.InstanceName})
+			}
+			if keypair != nil {
+				s.attachKeyPair(keypair, instance.InstanceID)
+			}
+		}
+
+		response.JobID = s.newJob("RunInstances", func() {
+			for _, instance := range instances {
+				s.setInstanceStatus(instance, anchnet.InstanceStatusRunning)
+			}
+			for _, volume := range append(volumes, existingVolumes...) {
+				s.setVolumeStatus(volume, anchnet.VolumeStatusInUse)
+			}
+			for _, eip := range eips {
+				s.setEipStatus(eip, anchnet.EipStatusAssociated)
+			}
+			if existingEip != nil {
+				s.setEipStatus(existingEip, anchnet.EipStatusAssociated)
+			}
+		}, func() {
+			for _, instance := range instances {
+				s.detachInstance(instance.InstanceID)
+				s.setInstanceStatus(instance, anchnet.InstanceStatusSuspended)
+			}
+			for _, volume := range volumes {
+				s.setVolumeStatus(volume, anchnet.VolumeStatusSuspended)
+			}
+			for _, eip := range eips {
+				s.setEipStatus(eip, anchnet.EipStatusSuspended)
+			}
+		})
+		return response, nil
+	}
+
+	handlers["TerminateInstances"] = func(s *Server, body []byte) (interface{}, error) {
+		var request anchnet.TerminateInstancesRequest
+		if err := decode(body, &request); err != nil {
+			return nil, err
+		}
+		items, err := s.instancesIn(request.InstanceIDs, anchnet.InstanceStatusRunning, anchnet.InstanceStatusStopped, anchnet.InstanceStatusSuspended)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range request.VolumeIDs {
+			if s.volumes[id] == nil {
+				return nil, notFound("volume", id)
+			}
+		}
+		for _, id := range request.EipIDs {
+			if s.eips[id] == nil {
+				return nil, notFound("eip", id)
+			}
+		}
+		jobID := s.transitInstances("TerminateInstances", items, anchnet.InstanceStatusPending)
+		s.jobs[jobID].succeed = func() {
+			for _, item := range items {
+				s.detachInstance(item.InstanceID)
+				delete(s.instances, item.InstanceID)
+			}
+			for _, id := range request.VolumeIDs {
+				s.setVolumeStatus(s.volumes[id], anchnet.VolumeStatusDeleted)
+			}
+			for _, id := range request.EipIDs {
+				delete(s.eips, id)
+			}
+		}
+		return &anchnet.TerminateInstancesResponse{JobID: jobID}, nil
+	}
+
+	handlers["StartInstances"] = func(s *Server, body []byte) (interface{}, error) {
+		var request anchnet.StartInstancesRequest
+		if err := decode(body, &request); err != nil {
+			return nil, err
+		}
+		items, err := s.instancesIn(request.InstanceIDs, anchnet.InstanceStatusStopped)
+		if err != nil {
+			return nil, err
+		}
+		return &anchnet.StartInstancesResponse{JobID: s.transitInstances("StartInstances", items, anchnet.InstanceStatusRunning)}, nil
+	}
+
+	handlers["StopInstances"] = func(s *Server, body []byte) (interface{}, error) {
+		var request anchnet.StopInstancesRequest
+		if err := decode(body, &request); err != nil {
+			return nil, err
+		}
+		items, err := s.instancesIn(request.InstanceIDs, anchnet.InstanceStatusRunning)
+		if err != nil {
+			return nil, err
+		}
+		return &anchnet.StopInstancesResponse{JobID: s.transitInstances("StopInstances", items, anchnet.InstanceStatusStopped)}, nil
+	}
+
+	handlers["RestartInstances"] = func(s *Server, body []byte) (interface{}, error) {
+		var request anchnet.RestartInstancesRequest
+		if err := decode(body, &request); err != nil {
+			return nil, err
+		}
+		items, err := s.instancesIn(request.InstanceIDs, anchnet.InstanceStatusRunning)
+		if err != nil {
+			return nil, err
+		}
+		return &anchnet.RestartInstancesResponse{JobID: s.transitInstances("RestartInstances", items, anchnet.InstanceStatusRunning)}, nil
+	}
+
+	handlers["ResizeInstances"] = func(s *Server, body []byte) (interface{}, error) {
+		var request anchnet.ResizeInstancesRequest
+		if err := decode(body, &request); err != nil {
+			return nil, err
+		}
+		items, err := s.instancesIn(request.InstanceIDs, anchnet.InstanceStatusStopped)
+		if err != nil {
+			return nil, err
+		}
+		jobID := s.transitInstances("ResizeInstances", items, anchnet.InstanceStatusStopped)
+		succeed := s.jobs[jobID].succeed
+		s.jobs[jobID].succeed = func() {
+			succeed()
+			for _, item := range items {
+				if request.Cpu > 0 {
+					item.VcpusCurrent = request.Cpu
+				}
+				if request.Mem > 0 {
+					item.MemoryCurrent = request.Mem
+				}
+			}
+		}
+		return &anchnet.ResizeInstancesResponse{JobID: jobID}, nil
+	}
+
+	handlers["ResetLoginPasswd"] = func(s *Server, body []byte) (interface{}, error) {
+		var request anchnet.ResetLoginPasswdRequest
+		if err := decode(body, &request); err != nil {
+			return nil, err
+		}
+		if _, err := s.instancesIn(request.InstanceIDs, anchnet.InstanceStatusStopped); err != nil {
+			return nil, err
+		}
+		return &anchnet.ResetLoginPasswdResponse{JobID: s.newJob("ResetLoginPasswd", nil, nil)}, nil
+	}
+
+	handlers["ModifyInstanceAttributes"] = func(s *Server, body []byte) (interface{}, error) {
+		var request anchnet.ModifyInstanceAttributesRequest
+		if err := decode(body, &request); err != nil {
+			return nil, err
+		}
+		items, err := s.instancesIn([]string{request.InstanceID}, anchnet.InstanceStatusRunning, anchnet.InstanceStatusStopped)
+		if err != nil {
+			return nil, err
+		}
+		if request.InstanceName != "" {
+			items[0].InstanceName = request.InstanceName
+		}
+		if request.Description != "" {
+			items[0].Description = request.Description
+		}
+		return &anchnet.ModifyInstanceAttributesResponse{InstanceID: request.InstanceID, JobID: s.newJob("ModifyInstanceAttributes", nil, nil)}, nil
+	}
+}
@@ -0,0 +1,276 @@
+// Copyright 2015 anchnet-go authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package anchnettest
+
+import (
+	"strings"
+
+	anchnet "github.com/caicloud/anchnet-go"
+)
+
+// Implements fake anchnet loadbalancer related APIs, except listeners and backends.
+
+// loadBalancersIn returns loadbalancers of ids, or error if any of them doesn't
+// exist or isn't in one of statuses.
+func (s *Server) loadBalancersIn(ids []string, statuses ...anchnet.LoadBalancerStatus) ([]*anchnet.DescribeLoadBalancersItem, error) {
+	if len(ids) == 0 {
+		return nil, errorf(anchnet.ErrorCodeInvalidParameter, "no loadbalancer given")
+	}
+	var items []*anchnet.DescribeLoadBalancersItem
+	for _, id := range ids {
+		item, ok := s.lbs[id]
+		if !ok {
+			return nil, notFound("loadbalancer", id)
+		}
+		if !hasLoadBalancerStatus(item.Status, statuses) {
+			return nil, wrongStatus(id, item.Status)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func hasLoadBalancerStatus(status anchnet.LoadBalancerStatus, statuses []anchnet.LoadBalancerStatus) bool {
+	for _, s := range statuses {
+		if status == s {
+			return true
+		}
+	}
+	return false
+}
+
+// transitLoadBalancers sets loadbalancers pending, until job of action finishes:
+// they become 'to' if it succeeds, or their previous status if it fails.
+func (s *Server) transitLoadBalancers(action string, items []*anchnet.DescribeLoadBalancersItem, to anchnet.LoadBalancerStatus) string {
+	from := make(map[*anchnet.DescribeLoadBalancersItem]anchnet.LoadBalancerStatus)
+	for _, item := range items {
+		from[item] = item.Status
+		s.setLoadBalancerStatus(item, anchnet.LoadBalancerStatusPending)
+	}
+	return s.newJob(action, func() {
+		for _, item := range items {
+			s.setLoadBalancerStatus(item, to)
+		}
+	}, func() {
+		for _, item := range items {
+			s.setLoadBalancerStatus(item, from[item])
+		}
+	})
+}
+
+func (s *Server) setLoadBalancerStatus(item *anchnet.DescribeLoadBalancersItem, status anchnet.LoadBalancerStatus) {
+	item.Status = status
+	item.StatusTime = anchnet.NewTime(s.now())
+}
+
+// describeLoadBalancer returns a copy of a loadbalancer, along with its eips.
+func (s *Server) describeLoadBalancer(id string) anchnet.DescribeLoadBalancersItem {
+	item := *s.lbs[id]
+	item.Eips = nil
+	for _, eipID := range sortedKeys(s.eips) {
+		eip := s.eips[eipID]
+		if eip.Loadbalancer.LoadbalancerID == id {
+			item.Eips = append(item.Eips, anchnet.DescribeLoadBalancersEIP{EipID: eip.EipID, EipName: eip.EipName, EipAddr: eip.EipAddr})
+		}
+	}
+	return item
+}
+
+// detachLoadBalancer dissociates all eips from a loadbalancer.
+func (s *Server) detachLoadBalancer(id string) {
+	for _, eip := range s.eips {
+		if eip.Loadbalancer.LoadbalancerID == id {
+			eip.Loadbalancer = anchnet.DescribeEipsLoadbalancer{}
+			s.setEipStatus(eip, anchnet.EipStatusAvailable)
+		}
+	}
+}
+
+func init() {
+	handlers["DescribeLoadBalancers"] = func(s *Server, body []byte) (interface{}, error) {
+		var request anchnet.DescribeLoadBalancersRequest
+		if err := decode(body, &request); err != nil {
+			return nil, err
+		}
+		var ids []string
+		for _, id := range selectIDs(request.LoadbalancerIDs, func(id string) bool { return s.lbs[id] != nil }, func() []string { return sortedKeys(s.lbs) }) {
+			item := s.lbs[id]
+			if len(request.Status) > 0 && !hasLoadBalancerStatus(item.Status, request.Status) {
+				continue
+			}
+			// Like anchnet, deleted loadbalancers are only listed on request.
+			if item.Status == anchnet.LoadBalancerStatusDeleted && len(request.Status) == 0 && len(request.LoadbalancerIDs) == 0 {
+				continue
+			}
+			if !strings.Contains(item.LoadbalancerName, request.SearchWord) {
+				continue
+			}
+			ids = append(ids, id)
+		}
+		response := &anchnet.DescribeLoadBalancersResponse{TotalCount: len(ids)}
+		for _, id := range page(ids, request.Offset, request.Limit) {
+			response.ItemSet = append(response.ItemSet, s.describeLoadBalancer(id))
+		}
+		return response, nil
+	}
+
+	handlers["CreateLoadBalancer"] = func(s *Server, body []byte) (interface{}, error) {
+		var request anchnet.CreateLoadBalancerRequest
+		if err := decode(body, &request); err != nil {
+			return nil, err
+		}
+		product := request.Product
+		if product.Loadbalancer.Type < anchnet.LoadBalancerType20K || product.Loadbalancer.Type > anchnet.LoadBalancerType100K {
+			return nil, errorf(anchnet.ErrorCodeInvalidParameter, "invalid loadbalancer type %v", product.Loadbalancer.Type)
+		}
+		var eipIDs []string
+		for _, ip := range product.Eips {
+			eipIDs = append(eipIDs, ip.RefID)
+		}
+		var eips []*anchnet.DescribeEipsItem
+		if len(eipIDs) > 0 {
+			var err error
+			if eips, err = s.eipsIn(eipIDs, anchnet.EipStatusAvailable); err != nil {
+				return nil, err
+			}
+		}
+		var sg *anchnet.DescribeSecurityGroupsItem
+		if product.Firewall.RefID != "" {
+			var err error
+			if sg, err = s.securityGroup(product.Firewall.RefID); err != nil {
+				return nil, err
+			}
+		}
+
+		now := anchnet.NewTime(s.now())
+		item := &anchnet.DescribeLoadBalancersItem{
+			LoadbalancerID:   s.newID("lb"),
+			LoadbalancerName: product.Loadbalancer.Name,
+			LoadbalancerType: product.Loadbalancer.Type,
+			Status:           anchnet.LoadBalancerStatusPending,
+			CreateTime:       now,
+			StatusTime:       now,
+		}
+		if sg != nil {
+			item.SecurityGroup = anchnet.DescribeLoadBalancersSecurityGroup{SecurityGroupID: sg.SecurityGroupID, SecurityGroupName: sg.SecurityGroupName}
+		}
+		s.lbs[item.LoadbalancerID] = item
+		for _, eip := range eips {
+			eip.Loadbalancer = anchnet.DescribeEipsLoadbalancer{LoadbalancerID: item.LoadbalancerID, LoadbalancerName: item.LoadbalancerName}
+			s.setEipStatus(eip, anchnet.EipStatusPending)
+		}
+		jobID := s.newJob("CreateLoadBalancer", func() {
+			s.setLoadBalancerStatus(item, anchnet.LoadBalancerStatusActive)
+			for _, eip := range eips {
+				s.setEipStatus(eip, anchnet.EipStatusAssociated)
+			}
+		}, func() {
+			s.detachLoadBalancer(item.LoadbalancerID)
+			s.setLoadBalancerStatus(item, anchnet.LoadBalancerStatusSuspended)
+		})
+		return &anchnet.CreateLoadBalancerResponse{JobID: jobID, LoadbalancerID: item.LoadbalancerID}, nil
+	}
+
+	handlers["DeleteLoadBalancers"] = func(s *Server, body []byte) (interface{}, error) {
+		var request anchnet.DeleteLoadBalancersRequest
+		if err := decode(body, &request); err != nil {
+			return nil, err
+		}
+		items, err := s.loadBalancersIn(request.LoadbalancerIDs, anchnet.LoadBalancerStatusActive, anchnet.LoadBalancerStatusStopped, anchnet.LoadBalancerStatusSuspended)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range request.EipIDs {
+			if s.eips[id] == nil {
+				return nil, notFound("eip", id)
+			}
+		}
+		jobID := s.transitLoadBalancers("DeleteLoadBalancers", items, anchnet.LoadBalancerStatusDeleted)
+		succeed := s.jobs[jobID].succeed
+		s.jobs[jobID].succeed = func() {
+			succeed()
+			for _, item := range items {
+				s.detachLoadBalancer(item.LoadbalancerID)
+			}
+			for _, id := range request.EipIDs {
+				delete(s.eips, id)
+			}
+		}
+		return &anchnet.DeleteLoadBalancersResponse{JobID: jobID}, nil
+	}
+
+	handlers["StartLoadBalancer"] = func(s *Server, body []byte) (interface{}, error) {
+		var request anchnet.StartLoadBalancersRequest
+		if err := decode(body, &request); err != nil {
+			return nil, err
+		}
+		items, err := s.loadBalancersIn(request.LoadbalancerIDs, anchnet.LoadBalancerStatusStopped)
+		if err != nil {
+			return nil, err
+		}
+		return &anchnet.StartLoadBalancersResponse{JobID: s.transitLoadBalancers("StartLoadBalancer", items, anchnet.LoadBalancerStatusActive)}, nil
+	}
+
+	handlers["StopLoadBalancer"] = func(s *Server, body []byte) (interface{}, error) {
+		var request anchnet.StopLoadBalancersRequest
+		if err := decode(body, &request); err != nil {
+			return nil, err
+		}
+		items, err := s.loadBalancersIn(request.LoadbalancerIDs, anchnet.LoadBalancerStatusActive)
+		if err != nil {
+			return nil, err
+		}
+		return &anchnet.StopLoadBalancersResponse{JobID: s.transitLoadBalancers("StopLoadBalancer", items, anchnet.LoadBalancerStatusStopped)}, nil
+	}
+
+	handlers["ResizeLoadBalancers"] = func(s *Server, body []byte) (interface{}, error) {
+		var request anchnet.ResizeLoadBalancersRequest
+		if err := decode(body, &request); err != nil {
+			return nil, err
+		}
+		if request.LoadBalancerType < anchnet.LoadBalancerType20K || request.LoadBalancerType > anchnet.LoadBalancerType100K {
+			return nil, errorf(anchnet.ErrorCodeInvalidParameter, "invalid loadbalancer type %v", request.LoadBalancerType)
+		}
+		items, err := s.loadBalancersIn(request.LoadbalancerIDs, anchnet.LoadBalancerStatusStopped)
+		if err != nil {
+			return nil, err
+		}
+		jobID := s.transitLoadBalancers("ResizeLoadBalancers", items, anchnet.LoadBalancerStatusStopped)
+		succeed := s.jobs[jobID].succeed
+		s.jobs[jobID].succeed = func() {
+			succeed()
+			for _, item := range items {
+				item.LoadbalancerType = request.LoadBalancerType
+			}
+		}
+		return &anchnet.ResizeLoadBalancersResponse{JobID: jobID}, nil
+	}
+
+	handlers["ModifyLoadBalancerAttributes"] = func(s *Server, body []byte) (interface{}, error) {
+		var request anchnet.ModifyLoadBalancerAttributesRequest
+		if err := decode(body, &request); err != nil {
+			return nil, err
+		}
+		items, err := s.loadBalancersIn([]string{request.LoadbalancerID}, anchnet.LoadBalancerStatusActive, anchnet.LoadBalancerStatusStopped)
+		if err != nil {
+			return nil, err
+		}
+		item := items[0]
+		if request.SecurityGroupID != "" {
+			sg, err := s.securityGroup(request.SecurityGroupID)
+			if err != nil {
+				return nil, err
+			}
+			item.SecurityGroup = anchnet.DescribeLoadBalancersSecurityGroup{SecurityGroupID: sg.SecurityGroupID, SecurityGroupName: sg.SecurityGroupName}
+		}
+		if request.LoadbalancerName != "" {
+			item.LoadbalancerName = request.LoadbalancerName
+		}
+		if request.Description != "" {
+			item.Description = request.Description
+		}
+		return &anchnet.ModifyLoadBalancerAttributesResponse{JobID: s.newJob("ModifyLoadBalancerAttributes", nil, nil), LoadbalancerID: item.LoadbalancerID}, nil
+	}
+}
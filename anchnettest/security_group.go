@@ -0,0 +1,251 @@
+// Copyright 2015 anchnet-go authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package anchnettest
+
+import (
+	"strings"
+
+	anchnet "github.com/caicloud/anchnet-go"
+)
+
+// Implements fake anchnet security group related APIs. Like vxnets, security groups
+// don't have status; changes to them are applied right away.
+
+// securityGroup returns the security group of id, or error if it doesn't exist.
+func (s *Server) securityGroup(id string) (*anchnet.DescribeSecurityGroupsItem, error) {
+	item, ok := s.sgs[id]
+	if !ok {
+		return nil, notFound("security group", id)
+	}
+	return item, nil
+}
+
+// securityGroupInUse returns true if the security group of id is applied to any
+// instance or loadbalancer.
+func (s *Server) securityGroupInUse(id string) bool {
+	for _, instance := range s.instances {
+		if instance.SecurityGroup.SecurityGroupID == id {
+			return true
+		}
+	}
+	for _, lb := range s.lbs {
+		if lb.SecurityGroup.SecurityGroupID == id && lb.Status != anchnet.LoadBalancerStatusDeleted {
+			return true
+		}
+	}
+	return false
+}
+
+// addSecurityGroupRule adds a rule to a security group and returns its ID.
+func (s *Server) addSecurityGroupRule(sg *anchnet.DescribeSecurityGroupsItem, rule anchnet.DescribeSecurityGroupRule) string {
+	rule.SecurityGroupRuleID = s.newID("sgr")
+	sg.SecurityGroupRules = append(sg.SecurityGroupRules, rule)
+	return rule.SecurityGroupRuleID
+}
+
+// findSecurityGroupRule returns the security group of a rule, and index of the
+// rule in the group.
+func (s *Server) findSecurityGroupRule(id string) (*anchnet.DescribeSecurityGroupsItem, int, error) {
+	for _, sg := range s.sgs {
+		for i, rule := range sg.SecurityGroupRules {
+			if rule.SecurityGroupRuleID == id {
+				return sg, i, nil
+			}
+		}
+	}
+	return nil, 0, notFound("security group rule", id)
+}
+
+func init() {
+	handlers["DescribeSecurityGroups"] = func(s *Server, body []byte) (interface{}, error) {
+		var request anchnet.DescribeSecurityGroupsRequest
+		if err := decode(body, &request); err != nil {
+			return nil, err
+		}
+		var ids []string
+		for _, id := range selectIDs(request.SecurityGroupIDs, func(id string) bool { return s.sgs[id] != nil }, func() []string { return sortedKeys(s.sgs) }) {
+			if strings.Contains(s.sgs[id].SecurityGroupName, request.SearchWord) {
+				ids = append(ids, id)
+			}
+		}
+		response := &anchnet.DescribeSecurityGroupsResponse{TotalCount: len(ids)}
+		for _, id := range page(ids, request.Offset, request.Limit) {
+			item := *s.sgs[id]
+			item.IsApplied = 0
+			if s.securityGroupInUse(id) {
+				item.IsApplied = 1
+			}
+			response.ItemSet = append(response.ItemSet, item)
+		}
+		return response, nil
+	}
+
+	handlers["CreateSecurityGroup"] = func(s *Server, body []byte) (interface{}, error) {
+		var request anchnet.CreateSecurityGroupRequest
+		if err := decode(body, &request); err != nil {
+			return nil, err
+		}
+		sg := &anchnet.DescribeSecurityGroupsItem{
+			SecurityGroupID:   s.newID("sg"),
+			SecurityGroupName: request.SecurityGroupName,
+			CreateTime:        anchnet.NewTime(s.now()),
+		}
+		for _, rule := range request.SecurityGroupRules {
+			s.addSecurityGroupRule(sg, anchnet.DescribeSecurityGroupRule{
+				SecurityGroupRuleName: rule.SecurityGroupRuleName,
+				Action:                rule.Action,
+				Direction:             rule.Direction,
+				Protocol:              rule.Protocol,
+				Disabled:              rule.Disabled,
+				Priority:              rule.Priority,
+				Value1:                rule.Value1,
+				Value2:                rule.Value2,
+				Value3:                rule.Value3,
+			})
+		}
+		s.sgs[sg.SecurityGroupID] = sg
+		return &anchnet.CreateSecurityGroupResponse{JobID: s.newJob("CreateSecurityGroup", nil, nil), SecurityGroupID: sg.SecurityGroupID}, nil
+	}
+
+	handlers["DeleteSecurityGroups"] = func(s *Server, body []byte) (interface{}, error) {
+		var request anchnet.DeleteSecurityGroupsRequest
+		if err := decode(body, &request); err != nil {
+			return nil, err
+		}
+		for _, id := range request.SecurityGroupIDs {
+			if _, err := s.securityGroup(id); err != nil {
+				return nil, err
+			}
+			if s.securityGroupInUse(id) {
+				return nil, errorf(anchnet.ErrorCodePermissionDenied, "security group %v is in use", id)
+			}
+		}
+		for _, id := range request.SecurityGroupIDs {
+			delete(s.sgs, id)
+		}
+		return &anchnet.DeleteSecurityGroupsResponse{JobID: s.newJob("DeleteSecurityGroups", nil, nil)}, nil
+	}
+
+	handlers["ApplySecurityGroup"] = func(s *Server, body []byte) (interface{}, error) {
+		var request anchnet.ApplySecurityGroupRequest
+		if err := decode(body, &request); err != nil {
+			return nil, err
+		}
+		sg, err := s.securityGroup(request.SecurityGroupID)
+		if err != nil {
+			return nil, err
+		}
+		instances, err := s.instancesIn(request.InstanceIDs, anchnet.InstanceStatusRunning, anchnet.InstanceStatusStopped)
+		if err != nil {
+			return nil, err
+		}
+		jobID := s.newJob("ApplySecurityGroup", func() {
+			for _, instance := range instances {
+				instance.SecurityGroup = anchnet.DescribeInstancesSecurityGroup{
+					Attachon:          1,
+					SecurityGroupID:   sg.SecurityGroupID,
+					SecurityGroupName: sg.SecurityGroupName,
+				}
+			}
+		}, nil)
+		return &anchnet.ApplySecurityGroupResponse{JobID: jobID}, nil
+	}
+
+	handlers["ModifySecurityGroupAttributes"] = func(s *Server, body []byte) (interface{}, error) {
+		var request anchnet.ModifySecurityGroupAttributesRequest
+		if err := decode(body, &request); err != nil {
+			return nil, err
+		}
+		sg, err := s.securityGroup(request.SecurityGroupID)
+		if err != nil {
+			return nil, err
+		}
+		if request.SecurityGroupName != "" {
+			sg.SecurityGroupName = request.SecurityGroupName
+		}
+		if request.Description != "" {
+			sg.Description = request.Description
+		}
+		return &anchnet.ModifySecurityGroupAttributesResponse{JobID: s.newJob("ModifySecurityGroupAttributes", nil, nil), SecurityGroupID: sg.SecurityGroupID}, nil
+	}
+
+	handlers["DescribeSecurityGroupRules"] = func(s *Server, body []byte) (interface{}, error) {
+		var request anchnet.DescribeSecurityGroupRulesRequest
+		if err := decode(body, &request); err != nil {
+			return nil, err
+		}
+		wanted := make(map[string]bool)
+		for _, id := range request.SecurityGroupRuleIDs {
+			wanted[id] = true
+		}
+		var sgs []*anchnet.DescribeSecurityGroupsItem
+		if request.SecurityGroupID != "" {
+			sg, err := s.securityGroup(request.SecurityGroupID)
+			if err != nil {
+				return nil, err
+			}
+			sgs = append(sgs, sg)
+		} else {
+			for _, id := range sortedKeys(s.sgs) {
+				sgs = append(sgs, s.sgs[id])
+			}
+		}
+		response := &anchnet.DescribeSecurityGroupRulesResponse{}
+		for _, sg := range sgs {
+			for _, rule := range sg.SecurityGroupRules {
+				if len(wanted) == 0 || wanted[rule.SecurityGroupRuleID] {
+					response.ItemSet = append(response.ItemSet, rule)
+				}
+			}
+		}
+		response.TotalCount = len(response.ItemSet)
+		return response, nil
+	}
+
+	handlers["AddSecurityGroupRules"] = func(s *Server, body []byte) (interface{}, error) {
+		var request anchnet.AddSecurityGroupRulesRequest
+		if err := decode(body, &request); err != nil {
+			return nil, err
+		}
+		sg, err := s.securityGroup(request.SecurityGroupID)
+		if err != nil {
+			return nil, err
+		}
+		response := &anchnet.AddSecurityGroupRulesResponse{}
+		for _, rule := range request.SecurityGroupRules {
+			id := s.addSecurityGroupRule(sg, anchnet.DescribeSecurityGroupRule{
+				SecurityGroupRuleName: rule.SecurityGroupRuleName,
+				Action:                rule.Action,
+				Direction:             rule.Direction,
+				Protocol:              rule.Protocol,
+				Disabled:              rule.Disabled,
+				Priority:              rule.Priority,
+				Value1:                rule.Value1,
+				Value2:                rule.Value2,
+				Value3:                rule.Value3,
+			})
+			response.SecurityGroupRuleIDs = append(response.SecurityGroupRuleIDs, id)
+		}
+		response.JobID = s.newJob("AddSecurityGroupRules", nil, nil)
+		return response, nil
+	}
+
+	handlers["DeleteSecurityGroupRules"] = func(s *Server, body []byte) (interface{}, error) {
+		var request anchnet.DeleteSecurityGroupRulesRequest
+		if err := decode(body, &request); err != nil {
+			return nil, err
+		}
+		for _, id := range request.SecurityGroupRuleIDs {
+			if _, _, err := s.findSecurityGroupRule(id); err != nil {
+				return nil, err
+			}
+		}
+		for _, id := range request.SecurityGroupRuleIDs {
+			sg, i, _ := s.findSecurityGroupRule(id)
+			sg.SecurityGroupRules = append(sg.SecurityGroupRules[:i], sg.SecurityGroupRules[i+1:]...)
+		}
+		return &anchnet.DeleteSecurityGroupRulesResponse{JobID: s.newJob("DeleteSecurityGroupRules", nil, nil)}, nil
+	}
+}
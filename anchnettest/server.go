@@ -0,0 +1,363 @@
+// Copyright 2015 anchnet-go authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package anchnettest provides an in-process fake anchnet API for end-to-end tests
+// of code built on anchnet.Client:
+//   server := anchnettest.NewServer()
+//   defer server.Close()
+//   client := server.Client()
+//
+// The fake keeps instances, volumes, eips, vxnets, loadbalancers, security groups,
+// keypairs and jobs in memory. Like anchnet, it verifies the request signature, rejects
+// actions on resources in the wrong status, and applies changes through jobs:
+// resources stay in a transient status, e.g. pending, until their job finishes
+// JobDuration later. Only the commonly used actions are implemented; others are
+// rejected with ErrorCodeInvalidParameter.
+package anchnettest
+
+import (
+	"crypto/hmac"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	anchnet "github.com/caicloud/anchnet-go"
+)
+
+// API keys accepted by a new Server. Use AddKey to accept others.
+const (
+	PublicKey  = "E5I9QKJF1O2B5PXE68LG"
+	PrivateKey = "secret"
+)
+
+// DefaultJobDuration is the JobDuration of a new Server.
+const DefaultJobDuration = 50 * time.Millisecond
+
+// Server is a fake anchnet API, serving http on URL.
+type Server struct {
+	URL string
+	// JobDuration is how long a job works before it finishes. Zero means jobs
+	// finish before the next request is handled.
+	JobDuration time.Duration
+
+	server *httptest.Server
+
+	mu     sync.Mutex
+	offset time.Duration // Added to real time, see Advance
+	seq    int
+	keys   map[string]string
+	errors map[string][]*apiError // Injected errors by action
+	fails  map[string]int        // Number of jobs to fail by action
+	jobs   map[string]*job
+
+	instances map[string]*anchnet.DescribeInstancesItem
+	volumes   map[string]*anchnet.DescribeVolumesItem
+	eips      map[string]*anchnet.DescribeEipsItem
+	vxnets    map[string]*anchnet.DescribeVxnetsItem
+	lbs       map[string]*anchnet.DescribeLoadBalancersItem
+	sgs       map[string]*anchnet.DescribeSecurityGroupsItem
+	keypairs  map[string]*anchnet.DescribeKeyPairsItem
+}
+
+// NewServer starts and returns a new fake anchnet server. The caller should call
+// Close when finished, to shut it down.
+func NewServer() *Server {
+	s := &Server{
+		JobDuration: DefaultJobDuration,
+		keys:        map[string]string{PublicKey: PrivateKey},
+		errors:      make(map[string][]*apiError),
+		fails:       make(map[string]int),
+		jobs:        make(map[string]*job),
+		instances:   make(map[string]*anchnet.DescribeInstancesItem),
+		volumes:     make(map[string]*anchnet.DescribeVolumesItem),
+		eips:        make(map[string]*anchnet.DescribeEipsItem),
+		vxnets:      make(map[string]*anchnet.DescribeVxnetsItem),
+		lbs:         make(map[string]*anchnet.DescribeLoadBalancersItem),
+		sgs:         make(map[string]*anchnet.DescribeSecurityGroupsItem),
+		keypairs:    make(map[string]*anchnet.DescribeKeyPairsItem),
+	}
+	s.server = httptest.NewServer(s)
+	s.URL = s.server.URL
+	return s
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// Client returns a client of the server, using the default API keys.
+func (s *Server) Client() *anchnet.Client {
+	client, _ := anchnet.NewClient(s.URL, &anchnet.AuthConfiguration{PublicKey: PublicKey, PrivateKey: PrivateKey})
+	return client
+}
+
+// AddKey makes the server accept requests signed with privateKey, with token
+// publicKey.
+func (s *Server) AddKey(publicKey, privateKey string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys[publicKey] = privateKey
+}
+
+// Advance moves the clock of the server forward by d, finishing jobs due by then.
+// It lets tests use a long JobDuration without sleeping.
+func (s *Server) Advance(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.offset += d
+	s.advanceJobs()
+}
+
+// InjectError makes the next request of action fail with given error code and
+// message, before it changes anything. Errors injected for the same action are
+// returned in order.
+func (s *Server) InjectError(action string, code int, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errors[action] = append(s.errors[action], &apiError{code: code, message: message})
+}
+
+// FailNextJob makes the next job of action fail instead of succeed. Resources
+// created by a failed job become suspended; other changes are not applied.
+func (s *Server) FailNextJob(action string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fails[action]++
+}
+
+func (s *Server) now() time.Time {
+	return time.Now().Add(s.offset)
+}
+
+// newID returns a new resource ID with given prefix, e.g. "i". IDs sort in the
+// order they are created.
+func (s *Server) newID(prefix string) string {
+	s.seq++
+	return fmt.Sprintf("%v-%08d", prefix, s.seq)
+}
+
+// handler handles a request of an action. body is the json request; it returns
+// the response, which must embed anchnet.ResponseCommon.
+type handler func(s *Server, body []byte) (interface{}, error)
+
+// handlers are all implemented actions.
+var handlers = make(map[string]handler)
+
+// apiError is an error returned by handlers, sent as ResponseCommon.
+type apiError struct {
+	code    int
+	message string
+}
+
+func (e *apiError) Error() string {
+	return e.message
+}
+
+func errorf(code int, format string, args ...interface{}) error {
+	return &apiError{code: code, message: fmt.Sprintf(format, args...)}
+}
+
+func notFound(kind, id string) error {
+	return errorf(anchnet.ErrorCodeResourceNotFound, "%v %v not found", kind, id)
+}
+
+func wrongStatus(id string, status interface{}) error {
+	return errorf(anchnet.ErrorCodePermissionDenied, "resource %v is %v", id, status)
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var common anchnet.RequestCommon
+	if err := json.Unmarshal(body, &common); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.advanceJobs()
+
+	response, err := s.handle(common, r.Header.Get("signature"), body)
+	if err != nil {
+		rc := &anchnet.ResponseCommon{Code: anchnet.ErrorCodeInternalError, Message: err.Error()}
+		if apiErr, ok := err.(*apiError); ok {
+			rc = &anchnet.ResponseCommon{Code: apiErr.code, Message: apiErr.message}
+		}
+		rc.RetCode = rc.Code
+		response = rc
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+func (s *Server) handle(common anchnet.RequestCommon, signature string, body []byte) (interface{}, error) {
+	privateKey, ok := s.keys[common.Token]
+	if !ok {
+		return nil, errorf(anchnet.ErrorCodeAuthFailure, "unknown token %q", common.Token)
+	}
+	expected := anchnet.GenSignature(body, []byte(privateKey))
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return nil, errorf(anchnet.ErrorCodeAuthFailure, "signature mismatch")
+	}
+
+	h, ok := handlers[common.Action]
+	if !ok {
+		return nil, errorf(anchnet.ErrorCodeInvalidParameter, "unsupported action %q", common.Action)
+	}
+	if injected := s.errors[common.Action]; len(injected) > 0 {
+		s.errors[common.Action] = injected[1:]
+		return nil, injected[0]
+	}
+
+	response, err := h(s, body)
+	if err != nil {
+		return nil, err
+	}
+	// Set action of the response, e.g. RunInstancesResponse.
+	rc := reflect.ValueOf(response).Elem().FieldByName("ResponseCommon")
+	rc.FieldByName("Action").SetString(common.Action + "Response")
+	return response, nil
+}
+
+// decode unmarshals request body into request, for handlers.
+func decode(body []byte, request interface{}) error {
+	if err := json.Unmarshal(body, request); err != nil {
+		return errorf(anchnet.ErrorCodeInvalidParameter, "invalid request: %v", err)
+	}
+	return nil
+}
+
+// job is an anchnet job. succeed is called when the job finishes successfully,
+// fail when it fails.
+type job struct {
+	item    anchnet.DescribeJobsItem
+	done    time.Time
+	failed  bool
+	succeed func()
+	fail    func()
+}
+
+// newJob creates a job of action. Either succeed or fail is called when the job
+// finishes, JobDuration later; both can be nil.
+func (s *Server) newJob(action string, succeed, fail func()) string {
+	now := s.now()
+	j := &job{
+		item: anchnet.DescribeJobsItem{
+			JobID:      s.newID("job"),
+			JobAction:  action,
+			Status:     anchnet.JobStatusPending,
+			CreateTime: anchnet.NewTime(now),
+			StatusTime: anchnet.NewTime(now),
+		},
+		done:    now.Add(s.JobDuration),
+		succeed: succeed,
+		fail:    fail,
+	}
+	if s.fails[action] > 0 {
+		s.fails[action]--
+		j.failed = true
+	}
+	s.jobs[j.item.JobID] = j
+	return j.item.JobID
+}
+
+// advanceJobs updates status of unfinished jobs, finishing those due, in the
+// order they were created.
+func (s *Server) advanceJobs() {
+	now := s.now()
+	var ids []string
+	for id, j := range s.jobs {
+		if j.item.Status == anchnet.JobStatusPending || j.item.Status == anchnet.JobStatusWorking {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		j := s.jobs[id]
+		switch {
+		case now.Before(j.done):
+			if j.item.Status == anchnet.JobStatusPending {
+				j.item.Status = anchnet.JobStatusWorking
+				j.item.StatusTime = anchnet.NewTime(now)
+			}
+			continue
+		case j.failed:
+			j.item.Status = anchnet.JobStatusFailed
+			if j.fail != nil {
+				j.fail()
+			}
+		default:
+			j.item.Status = anchnet.JobStatusSuccessful
+			if j.succeed != nil {
+				j.succeed()
+			}
+		}
+		j.item.StatusTime = anchnet.NewTime(now)
+	}
+}
+
+// page returns the range of sorted ids selected by offset and limit.
+func page(ids []string, offset, limit int) []string {
+	sort.Strings(ids)
+	if offset >= len(ids) {
+		return nil
+	}
+	ids = ids[offset:]
+	if limit > 0 && limit < len(ids) {
+		ids = ids[:limit]
+	}
+	return ids
+}
+
+// sortedKeys returns sorted keys of a map of resources, keyed by ID.
+func sortedKeys(resources interface{}) []string {
+	var ids []string
+	for _, key := range reflect.ValueOf(resources).MapKeys() {
+		ids = append(ids, key.String())
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// selectIDs returns requested IDs which exist in resources, or all IDs of
+// resources if none is requested.
+func selectIDs(requested []string, exists func(id string) bool, all func() []string) []string {
+	if len(requested) == 0 {
+		return all()
+	}
+	var ids []string
+	for _, id := range requested {
+		if exists(id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func init() {
+	handlers["DescribeJobs"] = func(s *Server, body []byte) (interface{}, error) {
+		var request anchnet.DescribeJobsRequest
+		if err := decode(body, &request); err != nil {
+			return nil, err
+		}
+		response := &anchnet.DescribeJobsResponse{}
+		for _, id := range request.JobIDs {
+			if j, ok := s.jobs[id]; ok {
+				response.ItemSet = append(response.ItemSet, j.item)
+			}
+		}
+		response.TotalCount = len(response.ItemSet)
+		return response, nil
+	}
+}
@@ -0,0 +1,248 @@
+// Copyright 2015 anchnet-go authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package anchnettest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	anchnet "github.com/caicloud/anchnet-go"
+)
+
+var waitOpts = &anchnet.WaitOptions{Interval: 10 * time.Millisecond, Timeout: 5 * time.Second}
+
+// TestInstanceLifecycle runs, stops and terminates an instance with the real client.
+func TestInstanceLifecycle(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	client := server.Client()
+	ctx := context.Background()
+
+	run, err := client.RunInstances(ctx, &anchnet.RunInstancesRequest{
+		Product: anchnet.RunInstancesProduct{
+			Cloud: anchnet.RunInstancesCloud{
+				VM:   anchnet.RunInstancesVM{Name: "test", LoginMode: anchnet.LoginModePwd, Password: "caicloud2015ABC", Mem: 1024, Cpu: 1, ImageID: "opensuse12x64c"},
+				HD:   []anchnet.RunInstancesHardDisk{{Name: "data", Type: anchnet.HDTypePerformance, Unit: 10}},
+				Net0: true,
+				Net1: []anchnet.RunInstancesNet1{{VxnetName: "private", Checked: true}},
+				IP:   anchnet.RunInstancesIP{Bandwidth: 1, IPGroup: anchnet.IPGroupBGP},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected non-nil error %v", err)
+	}
+	if len(run.InstanceIDs) != 1 || len(run.VolumeIDs) != 1 || len(run.EipIDs) != 1 {
+		t.Fatalf("Expected one instance, volume and eip, got %+v", run)
+	}
+	if err := client.WaitJob(ctx, run.JobID, waitOpts); err != nil {
+		t.Fatalf("Unexpected non-nil error %v", err)
+	}
+
+	describe, err := client.DescribeInstances(ctx, &anchnet.DescribeInstancesRequest{InstanceIDs: run.InstanceIDs})
+	if err != nil {
+		t.Fatalf("Unexpected non-nil error %v", err)
+	}
+	item := describe.ItemSet[0]
+	if item.Status != anchnet.InstanceStatusRunning || item.EIP.EipID != run.EipIDs[0] ||
+		len(item.VolumeIDs) != 1 || item.VolumeIDs[0] != run.VolumeIDs[0] || len(item.Vxnets) != 1 {
+		t.Errorf("Unexpected instance %+v", item)
+	}
+
+	// Running instances can't be started.
+	_, err = client.StartInstances(ctx, &anchnet.StartInstancesRequest{InstanceIDs: run.InstanceIDs})
+	if !anchnet.IsPermissionDenied(err) {
+		t.Errorf("Expected permission denied error, got %v", err)
+	}
+
+	// Running instances are stopped to be resized, then started again.
+	resize := &anchnet.ResizeInstancesRequest{InstanceIDs: run.InstanceIDs, Cpu: 2, Mem: 4096}
+	if _, err := client.ResizeAndRestartInstances(ctx, resize, waitOpts); err != nil {
+		t.Fatalf("Unexpected non-nil error %v", err)
+	}
+	describe, err = client.DescribeInstances(ctx, &anchnet.DescribeInstancesRequest{InstanceIDs: run.InstanceIDs})
+	if err != nil {
+		t.Fatalf("Unexpected non-nil error %v", err)
+	}
+	if item = describe.ItemSet[0]; item.Status != anchnet.InstanceStatusRunning || item.VcpusCurrent != 2 || item.MemoryCurrent != 4096 {
+		t.Errorf("Unexpected resized instance %+v", item)
+	}
+
+	// Instances are started again if resizing fails.
+	server.FailNextJob("ResizeInstances")
+	_, err = client.ResizeAndRestartInstances(ctx, &anchnet.ResizeInstancesRequest{InstanceIDs: run.InstanceIDs, Cpu: 4, Mem: 8192}, waitOpts)
+	if _, ok := err.(*anchnet.JobError); !ok {
+		t.Errorf("Expected job error, got %v", err)
+	}
+	describe, err = client.DescribeInstances(ctx, &anchnet.DescribeInstancesRequest{InstanceIDs: run.InstanceIDs})
+	if err != nil {
+		t.Fatalf("Unexpected non-nil error %v", err)
+	}
+	if item = describe.ItemSet[0]; item.Status != anchnet.InstanceStatusRunning || item.VcpusCurrent != 2 {
+		t.Errorf("Unexpected instance after failed resize %+v", item)
+	}
+	if _, err := client.ResizeAndRestartInstances(ctx, nil, waitOpts); err == nil {
+		t.Errorf("Expected error for nil request")
+	}
+
+	if _, err := client.StopInstances(ctx, &anchnet.StopInstancesRequest{InstanceIDs: run.InstanceIDs}); err != nil {
+		t.Fatalf("Unexpected non-nil error %v", err)
+	}
+	if err := client.WaitInstanceStatus(ctx, run.InstanceIDs, anchnet.InstanceStatusStopped, waitOpts); err != nil {
+		t.Fatalf("Unexpected non-nil error %v", err)
+	}
+
+	terminate, err := client.TerminateInstances(ctx, &anchnet.TerminateInstancesRequest{InstanceIDs: run.InstanceIDs, EipIDs: run.EipIDs})
+	if err != nil {
+		t.Fatalf("Unexpected non-nil error %v", err)
+	}
+	if err := client.WaitJob(ctx, terminate.JobID, waitOpts); err != nil {
+		t.Fatalf("Unexpected non-nil error %v", err)
+	}
+	if err := client.WaitVolumeStatus(ctx, run.VolumeIDs, anchnet.VolumeStatusAvailable, waitOpts); err != nil {
+		t.Errorf("Unexpected non-nil error %v", err)
+	}
+	eips, err := client.DescribeEips(ctx, &anchnet.DescribeEipsRequest{})
+	if err != nil || eips.TotalCount != 0 {
+		t.Errorf("Expected no eip, got %+v, %v", eips, err)
+	}
+}
+
+// TestSignature tests that requests signed with a wrong key are rejected.
+func TestSignature(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	client, _ := anchnet.NewClient(server.URL, &anchnet.AuthConfiguration{PublicKey: PublicKey, PrivateKey: "wrong"})
+	_, err := client.DescribeInstances(context.Background(), &anchnet.DescribeInstancesRequest{})
+	if !anchnet.IsAuthFailure(err) {
+		t.Errorf("Expected auth failure, got %v", err)
+	}
+
+	server.AddKey("other", "wrong")
+	client, _ = anchnet.NewClient(server.URL, &anchnet.AuthConfiguration{PublicKey: "other", PrivateKey: "wrong"})
+	if _, err = client.DescribeInstances(context.Background(), &anchnet.DescribeInstancesRequest{}); err != nil {
+		t.Errorf("Unexpected non-nil error %v", err)
+	}
+}
+
+// TestJobs tests that jobs advance with the clock, and fail on request.
+func TestJobs(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	server.JobDuration = time.Hour
+	client := server.Client()
+	ctx := context.Background()
+
+	create, err := client.CreateVolumes(ctx, &anchnet.CreateVolumesRequest{VolumeName: "test", VolumeType: anchnet.VolumeTypePerformance, Size: 10, Count: 2})
+	if err != nil {
+		t.Fatalf("Unexpected non-nil error %v", err)
+	}
+	jobStatus := func(jobID string) anchnet.JobStatus {
+		response, err := client.DescribeJobs(ctx, &anchnet.DescribeJobsRequest{JobIDs: []string{jobID}})
+		if err != nil || len(response.ItemSet) != 1 {
+			t.Fatalf("Unexpected response %+v, %v", response, err)
+		}
+		return response.ItemSet[0].Status
+	}
+	if status := jobStatus(create.JobID); status != anchnet.JobStatusWorking {
+		t.Errorf("Expected working job, got %v", status)
+	}
+	// Pending volumes can't be resized.
+	_, err = client.ResizeVolumes(ctx, &anchnet.ResizeVolumesRequest{VolumeIDs: create.VolumeIDs, Size: 20})
+	if !anchnet.IsPermissionDenied(err) {
+		t.Errorf("Expected permission denied error, got %v", err)
+	}
+
+	server.Advance(time.Hour)
+	if status := jobStatus(create.JobID); status != anchnet.JobStatusSuccessful {
+		t.Errorf("Expected successful job, got %v", status)
+	}
+
+	server.JobDuration = 0
+	server.FailNextJob("AttachVolumes")
+	server.InjectError("DescribeInstances", anchnet.ErrorCodeQuotaExceeded, "quota")
+	if _, err := client.DescribeInstances(ctx, &anchnet.DescribeInstancesRequest{}); !anchnet.IsQuotaExceeded(err) {
+		t.Errorf("Expected quota exceeded error, got %v", err)
+	}
+	run, err := client.RunInstances(ctx, &anchnet.RunInstancesRequest{
+		Product: anchnet.RunInstancesProduct{Cloud: anchnet.RunInstancesCloud{VM: anchnet.RunInstancesVM{Name: "test", LoginMode: anchnet.LoginModePwd, Password: "caicloud2015ABC", Mem: 1024, Cpu: 1, ImageID: "opensuse12x64c"}}},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected non-nil error %v", err)
+	}
+	if err := client.WaitJob(ctx, run.JobID, waitOpts); err != nil {
+		t.Fatalf("Unexpected non-nil error %v", err)
+	}
+	attach, err := client.AttachVolumes(ctx, &anchnet.AttachVolumesRequest{InstanceID: run.InstanceIDs[0], VolumeIDs: create.VolumeIDs})
+	if err != nil {
+		t.Fatalf("Unexpected non-nil error %v", err)
+	}
+	var jobErr *anchnet.JobError
+	if err := client.WaitJob(ctx, attach.JobID, waitOpts); !errors.As(err, &jobErr) {
+		t.Errorf("Expected job error, got %v", err)
+	}
+	if err := client.WaitVolumeStatus(ctx, create.VolumeIDs, anchnet.VolumeStatusAvailable, waitOpts); err != nil {
+		t.Errorf("Unexpected non-nil error %v", err)
+	}
+
+	// Unknown jobs are not found, instead of pending forever with default options.
+	timeout, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	err = client.WaitJob(timeout, "job-typo", nil)
+	if !errors.As(err, &jobErr) || jobErr.JobID != "job-typo" || jobErr.Job.Status != "" {
+		t.Errorf("Expected not found job error, got %v", err)
+	}
+}
+
+// TestKeyPairs runs an instance with a keypair, and detaches and deletes it.
+func TestKeyPairs(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	client := server.Client()
+	ctx := context.Background()
+
+	create, err := client.CreateKeyPair(ctx, &anchnet.CreateKeyPairRequest{KeyPairName: "deploy", Mode: anchnet.KeyPairModeUser, PublicKey: "ssh-rsa AAAAB3NzaC1yc2E deploy"})
+	if err != nil {
+		t.Fatalf("Unexpected non-nil error %v", err)
+	}
+	run, err := client.RunInstances(ctx, &anchnet.RunInstancesRequest{
+		Product: anchnet.RunInstancesProduct{Cloud: anchnet.RunInstancesCloud{
+			VM: anchnet.RunInstancesVM{Name: "test", LoginMode: anchnet.LoginModeKeypair, KeyPairID: create.KeyPairID, Mem: 1024, Cpu: 1, ImageID: "opensuse12x64c"},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected non-nil error %v", err)
+	}
+	if err := client.WaitJob(ctx, run.JobID, waitOpts); err != nil {
+		t.Fatalf("Unexpected non-nil error %v", err)
+	}
+	describe, err := client.DescribeKeyPairs(ctx, &anchnet.DescribeKeyPairsRequest{InstanceID: run.InstanceIDs[0]})
+	if err != nil || describe.TotalCount != 1 || describe.ItemSet[0].EncryptMethod != anchnet.KeyPairEncryptMethodRSA {
+		t.Errorf("Unexpected keypairs %+v, error %v", describe, err)
+	}
+
+	// Attached keypairs can't be deleted.
+	_, err = client.DeleteKeyPairs(ctx, &anchnet.DeleteKeyPairsRequest{KeyPairIDs: []string{create.KeyPairID}})
+	if !anchnet.IsPermissionDenied(err) {
+		t.Errorf("Expected permission denied error, got %v", err)
+	}
+	detach, err := client.DetachKeyPairs(ctx, &anchnet.DetachKeyPairsRequest{KeyPairIDs: []string{create.KeyPairID}, InstanceIDs: run.InstanceIDs})
+	if err != nil {
+		t.Fatalf("Unexpected non-nil error %v", err)
+	}
+	if err := client.WaitJob(ctx, detach.JobID, waitOpts); err != nil {
+		t.Fatalf("Unexpected non-nil error %v", err)
+	}
+	instances, err := client.DescribeInstances(ctx, &anchnet.DescribeInstancesRequest{InstanceIDs: run.InstanceIDs})
+	if err != nil || len(instances.ItemSet[0].KeyPairIDs) != 0 {
+		t.Errorf("Expected keypair detached, got %+v, error %v", instances, err)
+	}
+	if _, err := client.DeleteKeyPairs(ctx, &anchnet.DeleteKeyPairsRequest{KeyPairIDs: []string{create.KeyPairID}}); err != nil {
+		t.Errorf("Unexpected non-nil error %v", err)
+	}
+}
@@ -0,0 +1,231 @@
+// Copyright 2015 anchnet-go authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package anchnettest
+
+import (
+	"strings"
+
+	anchnet "github.com/caicloud/anchnet-go"
+)
+
+// Implements fake anchnet volume related APIs.
+
+// volumesIn returns volumes of ids, or error if any of them doesn't exist or
+// isn't in one of statuses.
+func (s *Server) volumesIn(ids []string, statuses ...anchnet.VolumeStatus) ([]*anchnet.DescribeVolumesItem, error) {
+	if len(ids) == 0 {
+		return nil, errorf(anchnet.ErrorCodeInvalidParameter, "no volume given")
+	}
+	var items []*anchnet.DescribeVolumesItem
+	for _, id := range ids {
+		item, ok := s.volumes[id]
+		if !ok {
+			return nil, notFound("volume", id)
+		}
+		if !hasVolumeStatus(item.Status, statuses) {
+			return nil, wrongStatus(id, item.Status)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func hasVolumeStatus(status anchnet.VolumeStatus, statuses []anchnet.VolumeStatus) bool {
+	for _, s := range statuses {
+		if status == s {
+			return true
+		}
+	}
+	return false
+}
+
+// transitVolumes sets volumes pending, until job of action finishes: they become
+// 'to' and change is called if it succeeds, or they get back their previous
+// status if it fails. change can be nil.
+func (s *Server) transitVolumes(action string, items []*anchnet.DescribeVolumesItem, to anchnet.VolumeStatus, change func(item *anchnet.DescribeVolumesItem)) string {
+	from := make(map[*anchnet.DescribeVolumesItem]anchnet.VolumeStatus)
+	for _, item := range items {
+		from[item] = item.Status
+		s.setVolumeStatus(item, anchnet.VolumeStatusPending)
+	}
+	return s.newJob(action, func() {
+		for _, item := range items {
+			if change != nil {
+				change(item)
+			}
+			s.setVolumeStatus(item, to)
+		}
+	}, func() {
+		for _, item := range items {
+			s.setVolumeStatus(item, from[item])
+		}
+	})
+}
+
+func (s *Server) setVolumeStatus(item *anchnet.DescribeVolumesItem, status anchnet.VolumeStatus) {
+	item.Status = status
+	item.StatusTime = anchnet.NewTime(s.now())
+}
+
+// createVolume creates a pending volume.
+func (s *Server) createVolume(name string, volumeType anchnet.VolumeType, size int) *anchnet.DescribeVolumesItem {
+	now := anchnet.NewTime(s.now())
+	item := &anchnet.DescribeVolumesItem{
+		VolumeID:   s.newID("vol"),
+		VolumeName: name,
+		VolumeType: volumeType,
+		Size:       anchnet.Int(size),
+		Status:     anchnet.VolumeStatusPending,
+		CreateTime: now,
+		StatusTime: now,
+	}
+	s.volumes[item.VolumeID] = item
+	return item
+}
+
+func init() {
+	handlers["DescribeVolumes"] = func(s *Server, body []byte) (interface{}, error) {
+		var request anchnet.DescribeVolumesRequest
+		if err := decode(body, &request); err != nil {
+			return nil, err
+		}
+		var ids []string
+		for _, id := range selectIDs(request.VolumeIDs, func(id string) bool { return s.volumes[id] != nil }, func() []string { return sortedKeys(s.volumes) }) {
+			item := s.volumes[id]
+			if len(request.Status) > 0 && !hasVolumeStatus(item.Status, request.Status) {
+				continue
+			}
+			// Like anchnet, deleted volumes are only listed on request.
+			if item.Status == anchnet.VolumeStatusDeleted && len(request.Status) == 0 && len(request.VolumeIDs) == 0 {
+				continue
+			}
+			if !strings.Contains(item.VolumeName, request.SearchWord) {
+				continue
+			}
+			ids = append(ids, id)
+		}
+		response := &anchnet.DescribeVolumesResponse{TotalCount: len(ids)}
+		for _, id := range page(ids, request.Offset, request.Limit) {
+			response.ItemSet = append(response.ItemSet, *s.volumes[id])
+		}
+		return response, nil
+	}
+
+	handlers["CreateVolumes"] = func(s *Server, body []byte) (interface{}, error) {
+		var request anchnet.CreateVolumesRequest
+		if err := decode(body, &request); err != nil {
+			return nil, err
+		}
+		if request.Size < 10 || request.Size > 1000 {
+			return nil, errorf(anchnet.ErrorCodeInvalidParameter, "volume size must be between 10GB and 1000GB, got %v", request.Size)
+		}
+		count := request.Count
+		if count <= 0 {
+			count = 1
+		}
+		response := &anchnet.CreateVolumesResponse{}
+		var items []*anchnet.DescribeVolumesItem
+		for i := 0; i < count; i++ {
+			item := s.createVolume(request.VolumeName, request.VolumeType, request.Size)
+			items = append(items, item)
+			response.VolumeIDs = append(response.VolumeIDs, item.VolumeID)
+		}
+		response.JobID = s.newJob("CreateVolumes", func() {
+			for _, item := range items {
+				s.setVolumeStatus(item, anchnet.VolumeStatusAvailable)
+			}
+		}, func() {
+			for _, item := range items {
+				s.setVolumeStatus(item, anchnet.VolumeStatusSuspended)
+			}
+		})
+		return response, nil
+	}
+
+	handlers["DeleteVolumes"] = func(s *Server, body []byte) (interface{}, error) {
+		var request anchnet.DeleteVolumesRequest
+		if err := decode(body, &request); err != nil {
+			return nil, err
+		}
+		items, err := s.volumesIn(request.VolumeIDs, anchnet.VolumeStatusAvailable, anchnet.VolumeStatusSuspended)
+		if err != nil {
+			return nil, err
+		}
+		jobID := s.transitVolumes("DeleteVolumes", items, anchnet.VolumeStatusDeleted, nil)
+		return &anchnet.DeleteVolumesResponse{JobID: jobID}, nil
+	}
+
+	handlers["AttachVolumes"] = func(s *Server, body []byte) (interface{}, error) {
+		var request anchnet.AttachVolumesRequest
+		if err := decode(body, &request); err != nil {
+			return nil, err
+		}
+		instances, err := s.instancesIn([]string{request.InstanceID}, anchnet.InstanceStatusRunning, anchnet.InstanceStatusStopped)
+		if err != nil {
+			return nil, err
+		}
+		items, err := s.volumesIn(request.VolumeIDs, anchnet.VolumeStatusAvailable)
+		if err != nil {
+			return nil, err
+		}
+		jobID := s.transitVolumes("AttachVolumes", items, anchnet.VolumeStatusInUse, func(item *anchnet.DescribeVolumesItem) {
+			item.Instance = anchnet.DescribeVolumesInstance{InstanceID: instances[0].InstanceID, InstanceName: instances[0].InstanceName}
+		})
+		return &anchnet.AttachVolumesResponse{JobID: jobID}, nil
+	}
+
+	handlers["DetachVolumes"] = func(s *Server, body []byte) (interface{}, error) {
+		var request anchnet.DetachVolumesRequest
+		if err := decode(body, &request); err != nil {
+			return nil, err
+		}
+		items, err := s.volumesIn(request.VolumeIDs, anchnet.VolumeStatusInUse)
+		if err != nil {
+			return nil, err
+		}
+		jobID := s.transitVolumes("DetachVolumes", items, anchnet.VolumeStatusAvailable, func(item *anchnet.DescribeVolumesItem) {
+			item.Instance = anchnet.DescribeVolumesInstance{}
+		})
+		return &anchnet.DetachVolumesResponse{JobID: jobID}, nil
+	}
+
+	handlers["ResizeVolumes"] = func(s *Server, body []byte) (interface{}, error) {
+		var request anchnet.ResizeVolumesRequest
+		if err := decode(body, &request); err != nil {
+			return nil, err
+		}
+		items, err := s.volumesIn(request.VolumeIDs, anchnet.VolumeStatusAvailable)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			if size := int(item.Size); request.Size <= size || request.Size > 1000 {
+				return nil, errorf(anchnet.ErrorCodeInvalidParameter, "can't resize volume %v from %vGB to %vGB", item.VolumeID, size, request.Size)
+			}
+		}
+		jobID := s.transitVolumes("ResizeVolumes", items, anchnet.VolumeStatusAvailable, func(item *anchnet.DescribeVolumesItem) {
+			item.Size = anchnet.Int(request.Size)
+		})
+		return &anchnet.ResizeVolumesResponse{JobID: jobID}, nil
+	}
+
+	handlers["ModifyVolumeAttributes"] = func(s *Server, body []byte) (interface{}, error) {
+		var request anchnet.ModifyVolumeAttributesRequest
+		if err := decode(body, &request); err != nil {
+			return nil, err
+		}
+		items, err := s.volumesIn([]string{request.VolumeID}, anchnet.VolumeStatusAvailable, anchnet.VolumeStatusInUse)
+		if err != nil {
+			return nil, err
+		}
+		if request.VolumeName != "" {
+			items[0].VolumeName = request.VolumeName
+		}
+		if request.Description != "" {
+			items[0].Description = request.Description
+		}
+		return &anchnet.ModifyVolumeAttributesResponse{JobID: s.newJob("ModifyVolumeAttributes", nil, nil)}, nil
+	}
+}
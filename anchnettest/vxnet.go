@@ -0,0 +1,183 @@
+// Copyright 2015 anchnet-go authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package anchnettest
+
+import (
+	"strings"
+
+	anchnet "github.com/caicloud/anchnet-go"
+)
+
+// Implements fake anchnet vxnet related APIs. Vxnets don't have status; they are
+// created and deleted right away, while instances join and leave them through jobs.
+
+// vxnet returns the vxnet of id, or error if it doesn't exist.
+func (s *Server) vxnet(id string) (*anchnet.DescribeVxnetsItem, error) {
+	item, ok := s.vxnets[id]
+	if !ok {
+		return nil, notFound("vxnet", id)
+	}
+	return item, nil
+}
+
+// createVxnet creates a vxnet.
+func (s *Server) createVxnet(name string, vxnetType anchnet.VxnetType) *anchnet.DescribeVxnetsItem {
+	item := &anchnet.DescribeVxnetsItem{
+		VxnetID:    s.newID("vxnet"),
+		VxnetName:  name,
+		VxnetType:  vxnetType,
+		Systype:    "priv",
+		CreateTime: anchnet.NewTime(s.now()),
+	}
+	if vxnetType == anchnet.VxnetTypePub {
+		item.Systype = "pub"
+	}
+	s.vxnets[item.VxnetID] = item
+	return item
+}
+
+// inVxnet returns true if instance of id is in vxnet.
+func inVxnet(vxnet *anchnet.DescribeVxnetsItem, id string) bool {
+	for _, instance := range vxnet.Instances {
+		if instance.InstanceID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// leaveVxnet removes instance of id from vxnet, if it's in the vxnet.
+func (s *Server) leaveVxnet(vxnet *anchnet.DescribeVxnetsItem, id string) {
+	var instances []anchnet.DescribeVxnetsInstance
+	for _, instance := range vxnet.Instances {
+		if instance.InstanceID != id {
+			instances = append(instances, instance)
+		}
+	}
+	vxnet.Instances = instances
+}
+
+func init() {
+	handlers["DescribeVxnets"] = func(s *Server, body []byte) (interface{}, error) {
+		var request anchnet.DescribeVxnetsRequest
+		if err := decode(body, &request); err != nil {
+			return nil, err
+		}
+		var ids []string
+		for _, id := range selectIDs(request.VxnetIDs, func(id string) bool { return s.vxnets[id] != nil }, func() []string { return sortedKeys(s.vxnets) }) {
+			if strings.Contains(s.vxnets[id].VxnetName, request.SearchWord) {
+				ids = append(ids, id)
+			}
+		}
+		response := &anchnet.DescribeVxnetsResponse{TotalCount: len(ids)}
+		for _, id := range page(ids, request.Offset, request.Limit) {
+			response.ItemSet = append(response.ItemSet, *s.vxnets[id])
+		}
+		return response, nil
+	}
+
+	handlers["CreateVxnets"] = func(s *Server, body []byte) (interface{}, error) {
+		var request anchnet.CreateVxnetsRequest
+		if err := decode(body, &request); err != nil {
+			return nil, err
+		}
+		count := request.Count
+		if count <= 0 {
+			count = 1
+		}
+		response := &anchnet.CreateVxnetsResponse{}
+		for i := 0; i < count; i++ {
+			response.VxnetIDs = append(response.VxnetIDs, s.createVxnet(request.VxnetName, request.VxnetType).VxnetID)
+		}
+		response.JobID = s.newJob("CreateVxnets", nil, nil)
+		return response, nil
+	}
+
+	handlers["DeleteVxnets"] = func(s *Server, body []byte) (interface{}, error) {
+		var request anchnet.DeleteVxnetsRequest
+		if err := decode(body, &request); err != nil {
+			return nil, err
+		}
+		for _, id := range request.VxnetIDs {
+			vxnet, err := s.vxnet(id)
+			if err != nil {
+				return nil, err
+			}
+			if len(vxnet.Instances) > 0 {
+				return nil, errorf(anchnet.ErrorCodePermissionDenied, "vxnet %v still has instances", id)
+			}
+		}
+		for _, id := range request.VxnetIDs {
+			delete(s.vxnets, id)
+		}
+		return &anchnet.DeleteVxnetsResponse{JobID: s.newJob("DeleteVxnets", nil, nil)}, nil
+	}
+
+	handlers["JoinVxnet"] = func(s *Server, body []byte) (interface{}, error) {
+		var request anchnet.JoinVxnetRequest
+		if err := decode(body, &request); err != nil {
+			return nil, err
+		}
+		vxnet, err := s.vxnet(request.VxnetID)
+		if err != nil {
+			return nil, err
+		}
+		instances, err := s.instancesIn(request.InstanceIDs, anchnet.InstanceStatusRunning, anchnet.InstanceStatusStopped)
+		if err != nil {
+			return nil, err
+		}
+		for _, instance := range instances {
+			if inVxnet(vxnet, instance.InstanceID) {
+				return nil, errorf(anchnet.ErrorCodeInvalidParameter, "instance %v is already in vxnet %v", instance.InstanceID, vxnet.VxnetID)
+			}
+		}
+		jobID := s.newJob("JoinVxnet", func() {
+			for _, instance := range instances {
+				vxnet.Instances = append(vxnet.Instances, anchnet.DescribeVxnetsInstance{InstanceID: instance.InstanceID, InstanceName: instance.InstanceName})
+			}
+		}, nil)
+		return &anchnet.JoinVxnetResponse{JobID: jobID}, nil
+	}
+
+	handlers["LeaveVxnet"] = func(s *Server, body []byte) (interface{}, error) {
+		var request anchnet.LeaveVxnetRequest
+		if err := decode(body, &request); err != nil {
+			return nil, err
+		}
+		vxnet, err := s.vxnet(request.VxnetID)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range request.InstanceIDs {
+			if !inVxnet(vxnet, id) {
+				return nil, errorf(anchnet.ErrorCodeInvalidParameter, "instance %v is not in vxnet %v", id, vxnet.VxnetID)
+			}
+		}
+		jobID := s.newJob("LeaveVxnet", func() {
+			for _, id := range request.InstanceIDs {
+				s.leaveVxnet(vxnet, id)
+			}
+		}, nil)
+		return &anchnet.LeaveVxnetResponse{JobID: jobID}, nil
+	}
+
+	handlers["ModifyVxnetAttributes"] = func(s *Server, body []byte) (interface{}, error) {
+		var request anchnet.ModifyVxnetAttributesRequest
+		if err := decode(body, &request); err != nil {
+			return nil, err
+		}
+		vxnet, err := s.vxnet(request.VxnetID)
+		if err != nil {
+			return nil, err
+		}
+		if request.VxnetName != "" {
+			vxnet.VxnetName = request.VxnetName
+		}
+		if request.Description != "" {
+			vxnet.Description = request.Description
+		}
+		return &anchnet.ModifyVxnetAttributesResponse{JobID: s.newJob("ModifyVxnetAttributes", nil, nil)}, nil
+	}
+}
@@ -5,41 +5,144 @@
 package anchnet
 
 import (
-	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"os"
 	"path"
+	"strings"
+	"sync"
+	"time"
 )
 
+const (
+	// Environment variables read by EnvProvider.
+	EnvPublicKey  = "ANCHNET_PUBLIC_KEY"
+	EnvPrivateKey = "ANCHNET_PRIVATE_KEY"
+	EnvProjectID  = "ANCHNET_PROJECT_ID"
+)
+
+// ErrNoCredentials is returned by credentials providers which have no API keys.
+var ErrNoCredentials = errors.New("no anchnet credentials found")
+
+// CredentialsProvider provides API keys to Client. Client calls Credentials
+// before every request, so keys can rotate without rebuilding the client.
+// Implementations must be safe for concurrent use.
+type CredentialsProvider interface {
+	Credentials() (*AuthConfiguration, error)
+}
+
+// AuthConfiguration holds anchnet API keys. It is a CredentialsProvider which
+// always returns itself.
 type AuthConfiguration struct {
 	PublicKey  string `json:"publickey"`
 	PrivateKey string `json:"privatekey"`
 	ProjectId  string `json:"projectid"`
 }
 
-// LoadConfig loads API keys from given path.
+// LoadConfig loads API keys from given path. If the file has named profiles, keys
+// of the default profile are returned, see LoadProfileConfig.
 func LoadConfig(path string) (*AuthConfiguration, error) {
 	r, err := os.Open(path)
 	if err != nil {
 		return nil, err
 	}
-	var auth AuthConfiguration
-	if err := json.NewDecoder(r).Decode(&auth); err != nil {
-		return nil, err
-	}
-	return &auth, nil
+	defer r.Close()
+	return LoadConfigReader(r)
 }
 
 // LoadConfigReader loads API keys from given reader.
 func LoadConfigReader(config io.Reader) (*AuthConfiguration, error) {
-	var auth AuthConfiguration
-	if err := json.NewDecoder(config).Decode(&auth); err != nil {
+	file, err := LoadProfileConfigReader(config)
+	if err != nil {
 		return nil, err
 	}
-	return &auth, nil
+	profile, err := file.Profile("")
+	if err != nil {
+		return nil, err
+	}
+	return &profile.AuthConfiguration, nil
 }
 
 // DefaultConfigPath get default configuration.
 func DefaultConfigPath() string {
 	return path.Join(os.Getenv("HOME"), ConfigDir, ConfigFile)
 }
+
+// Credentials implements CredentialsProvider.
+func (auth *AuthConfiguration) Credentials() (*AuthConfiguration, error) {
+	if auth == nil {
+		return nil, ErrNoCredentials
+	}
+	return auth, nil
+}
+
+// EnvProvider reads API keys from environment variables EnvPublicKey,
+// EnvPrivateKey and EnvProjectID (optional).
+type EnvProvider struct{}
+
+// Credentials implements CredentialsProvider.
+func (EnvProvider) Credentials() (*AuthConfiguration, error) {
+	auth := &AuthConfiguration{
+		PublicKey:  os.Getenv(EnvPublicKey),
+		PrivateKey: os.Getenv(EnvPrivateKey),
+		ProjectId:  os.Getenv(EnvProjectID),
+	}
+	if auth.PublicKey == "" || auth.PrivateKey == "" {
+		return nil, fmt.Errorf("%w: %v and %v must be set", ErrNoCredentials, EnvPublicKey, EnvPrivateKey)
+	}
+	return auth, nil
+}
+
+// FileProvider reads API keys from a config file in the same format as LoadConfig,
+// e.g. a mounted secret. The file is read again whenever its modification time
+// changes, so rotated keys are picked up.
+type FileProvider struct {
+	Path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	auth    *AuthConfiguration
+}
+
+// NewFileProvider creates a FileProvider reading from path.
+func NewFileProvider(path string) *FileProvider {
+	return &FileProvider{Path: path}
+}
+
+// Credentials implements CredentialsProvider.
+func (p *FileProvider) Credentials() (*AuthConfiguration, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	info, err := os.Stat(p.Path)
+	if err != nil {
+		return nil, err
+	}
+	if p.auth != nil && info.ModTime().Equal(p.modTime) {
+		return p.auth, nil
+	}
+	auth, err := LoadConfig(p.Path)
+	if err != nil {
+		return nil, err
+	}
+	p.auth, p.modTime = auth, info.ModTime()
+	return auth, nil
+}
+
+// ChainProvider tries each provider in turn, and returns credentials from the
+// first one which succeeds.
+type ChainProvider []CredentialsProvider
+
+// Credentials implements CredentialsProvider.
+func (chain ChainProvider) Credentials() (*AuthConfiguration, error) {
+	var errs []string
+	for _, provider := range chain {
+		auth, err := provider.Credentials()
+		if err == nil {
+			return auth, nil
+		}
+		errs = append(errs, err.Error())
+	}
+	return nil, fmt.Errorf("%w: %v", ErrNoCredentials, strings.Join(errs, "; "))
+}
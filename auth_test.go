@@ -0,0 +1,140 @@
+// Copyright 2015 anchnet-go authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package anchnet
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestEnvProvider tests reading credentials from environment.
+func TestEnvProvider(t *testing.T) {
+	t.Setenv(EnvPublicKey, "")
+	t.Setenv(EnvPrivateKey, "")
+	if _, err := (EnvProvider{}).Credentials(); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("Expected ErrNoCredentials, got %v", err)
+	}
+
+	t.Setenv(EnvPublicKey, "E5I9QKJF1O2B5PXE68LG")
+	t.Setenv(EnvPrivateKey, "secret")
+	t.Setenv(EnvProjectID, "pro-2OS5S5F6")
+	auth, err := EnvProvider{}.Credentials()
+	if err != nil {
+		t.Errorf("Unexpected non-nil error %v", err)
+	}
+	expected := &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret", ProjectId: "pro-2OS5S5F6"}
+	if !reflect.DeepEqual(expected, auth) {
+		t.Errorf("Error: expected \n%v, got \n%v", expected, auth)
+	}
+}
+
+// TestFileProvider tests that rotated keys are picked up.
+func TestFileProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	write := func(content string, modTime time.Time) {
+		if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatalf("Unexpected error writing config: %v", err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatalf("Unexpected error setting modification time: %v", err)
+		}
+	}
+
+	now := time.Now()
+	write(`{"publickey": "key1", "privatekey": "secret1"}`, now)
+	provider := NewFileProvider(path)
+	auth, err := provider.Credentials()
+	if err != nil || auth.PublicKey != "key1" {
+		t.Errorf("Expected key1, got %v, %v", auth, err)
+	}
+
+	write(`{"publickey": "key2", "privatekey": "secret2"}`, now.Add(time.Minute))
+	auth, err = provider.Credentials()
+	if err != nil || auth.PublicKey != "key2" {
+		t.Errorf("Expected key2, got %v, %v", auth, err)
+	}
+}
+
+// TestChainProvider tests that the first provider with credentials wins.
+func TestChainProvider(t *testing.T) {
+	t.Setenv(EnvPublicKey, "")
+	chain := ChainProvider{
+		EnvProvider{},
+		NewFileProvider(filepath.Join(t.TempDir(), "missing")),
+		&AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"},
+	}
+	auth, err := chain.Credentials()
+	if err != nil || auth.PublicKey != "E5I9QKJF1O2B5PXE68LG" {
+		t.Errorf("Expected static credentials, got %v, %v", auth, err)
+	}
+
+	if _, err := chain[:2].Credentials(); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("Expected ErrNoCredentials, got %v", err)
+	}
+}
+
+// rotatingProvider returns different keys on every call.
+type rotatingProvider struct {
+	keys []AuthConfiguration
+}
+
+func (p *rotatingProvider) Credentials() (*AuthConfiguration, error) {
+	auth := p.keys[0]
+	p.keys = p.keys[1:]
+	return &auth, nil
+}
+
+// TestClientCredentials tests that client consults its provider on each request.
+func TestClientCredentials(t *testing.T) {
+	var tokens []string
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		var request RequestCommon
+		if err := json.Unmarshal(body, &request); err != nil {
+			t.Errorf("Unexpected error unmarshaling request: %v", err)
+		}
+		tokens = append(tokens, request.Token)
+		if expected := GenSignature(body, []byte("secret-"+request.Token)); r.Header.Get("signature") != expected {
+			t.Errorf("Expected signature %v, got %v", expected, r.Header.Get("signature"))
+		}
+		w.Write([]byte(`{"code": 0}`))
+	}))
+	defer testServer.Close()
+
+	c, err := NewClientWithCredentials(testServer.URL, &rotatingProvider{keys: []AuthConfiguration{
+		{PublicKey: "key1", PrivateKey: "secret-key1"},
+		{PublicKey: "key2", PrivateKey: "secret-key2"},
+	}})
+	if err != nil {
+		t.Errorf("Unexpected non-nil error %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		var response DescribeJobsResponse
+		if err := c.SendRequest(DescribeJobsRequest{}, &response); err != nil {
+			t.Errorf("Unexpected non-nil error %v", err)
+		}
+	}
+	if expected := []string{"key1", "key2"}; !reflect.DeepEqual(expected, tokens) {
+		t.Errorf("Expected tokens %v, got %v", expected, tokens)
+	}
+}
+
+// TestClientNilCredentials tests that client can't be created without credentials.
+func TestClientNilCredentials(t *testing.T) {
+	if _, err := NewClientWithCredentials("http://localhost", nil); err == nil {
+		t.Errorf("Expected error for nil credentials")
+	}
+	if _, err := NewClient("http://localhost", nil); err == nil {
+		t.Errorf("Expected error for nil auth configuration")
+	}
+}
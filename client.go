@@ -14,12 +14,13 @@ package anchnet
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"reflect"
-	"strings"
+	"time"
 )
 
 const (
@@ -44,6 +45,7 @@ func init() {
 	actions["StartInstances"] = true
 	actions["StopInstances"] = true
 	actions["RestartInstances"] = true
+	actions["ResizeInstances"] = true
 	actions["ResetLoginPasswd"] = true
 	actions["ModifyInstanceAttributes"] = true
 
@@ -98,6 +100,12 @@ func init() {
 	actions["DeleteSecurityGroupRules"] = true
 	actions["ModifySecurityGroupRuleAttributes"] = true
 
+	actions["DescribeKeyPairs"] = true
+	actions["CreateKeyPair"] = true
+	actions["DeleteKeyPairs"] = true
+	actions["AttachKeyPairs"] = true
+	actions["DetachKeyPairs"] = true
+
 	actions["DescribeJobs"] = true
 
 	actions["CreateUserProject"] = true
@@ -113,19 +121,39 @@ func init() {
 	actions["DescribeImageUsers"] = true
 }
 
+// Request is implemented by all anchnet request types. ActionName returns the
+// name of the anchnet API the request is sent to, e.g. RunInstances. It is not
+// named Action to avoid clashing with RequestCommon.Action and with request
+// fields like ModifySecurityGroupRuleAttributesRequest.Action.
+type Request interface {
+	ActionName() string
+}
+
 // Client represents an anchnet client.
 type Client struct {
 	HTTPClient *http.Client
-
-	auth     *AuthConfiguration
-	endpoint string
-	zone     string
+	// RetryPolicy controls retries of transient failures, see DefaultRetryPolicy.
+	RetryPolicy RetryPolicy
+	// Metrics, if not nil, collects metrics of all requests, see NewMetrics.
+	Metrics MetricsCollector
+	// Logger, if not nil, logs every request and response at debug level, with
+	// secrets redacted, see Redact.
+	Logger Logger
+	// RateLimiter, if not nil, throttles requests, see NewRateLimiter.
+	RateLimiter *RateLimiter
+	// SkipValidation disables validating requests before sending them, see Validator.
+	SkipValidation bool
+
+	middlewares []Middleware
+	credentials CredentialsProvider
+	endpoint    string
+	zone        string
 }
 
 // RequestCommon is the common request options used in all requests. Unless strictly
 // necessary, client doesn't need to specify these. Action will be set per different
-// API, e.g. RunInstances, Token will be set by API method using auth.PublicKey, Zone
-// is set to "ac1" which is the only zone supported.
+// API, e.g. RunInstances, Token will be set by API method using PublicKey of client
+// credentials, Zone is set to "ac1" which is the only zone supported.
 // http://cloud.51idc.com/help/api/public_params.html
 type RequestCommon struct {
 	Action  string `json:"action,omitempty"`
@@ -145,13 +173,43 @@ type ResponseCommon struct {
 	Message string `json:"message,omitempty"`
 }
 
-// NewClient creates a new client.
+// commonRequest is implemented by pointers to all request types, through
+// embedded RequestCommon.
+type commonRequest interface {
+	requestCommon() *RequestCommon
+}
+
+func (r *RequestCommon) requestCommon() *RequestCommon {
+	return r
+}
+
+// commonResponse is implemented by pointers to all response types, through
+// embedded ResponseCommon.
+type commonResponse interface {
+	responseCommon() *ResponseCommon
+}
+
+func (r *ResponseCommon) responseCommon() *ResponseCommon {
+	return r
+}
+
+// NewClient creates a new client with fixed API keys.
 func NewClient(endpoint string, auth *AuthConfiguration) (*Client, error) {
+	return NewClientWithCredentials(endpoint, auth)
+}
+
+// NewClientWithCredentials creates a new client which gets API keys from the
+// given provider before every request, see CredentialsProvider.
+func NewClientWithCredentials(endpoint string, credentials CredentialsProvider) (*Client, error) {
+	if v := reflect.ValueOf(credentials); !v.IsValid() || v.Kind() == reflect.Ptr && v.IsNil() {
+		return nil, fmt.Errorf("expected non-nil credentials")
+	}
 	return &Client{
-		HTTPClient: http.DefaultClient,
-		auth:       auth,
-		endpoint:   endpoint,
-		zone:       DefaultZone,
+		HTTPClient:  http.DefaultClient,
+		RetryPolicy: DefaultRetryPolicy,
+		credentials: credentials,
+		endpoint:    endpoint,
+		zone:        DefaultZone,
 	}, nil
 }
 
@@ -161,10 +219,34 @@ func (c *Client) SetZone(zone string) {
 }
 
 // SendRequest sends request to anchnet and returns response. 'response' must be
-// a pointer value.
-func (c *Client) SendRequest(request interface{}, response interface{}) error {
-	if reflect.TypeOf(response).Kind() != reflect.Ptr {
-		return fmt.Errorf("expected pointer arg for response")
+// a pointer value. Prefer the typed per-action methods, e.g. Client.RunInstances,
+// which catch mismatched request and response types at compile time. If anchnet
+// rejects the request, the returned error is an *APIError.
+func (c *Client) SendRequest(request Request, response interface{}) error {
+	return c.SendRequestWithContext(context.Background(), request, response)
+}
+
+// SendRequestWithContext is like SendRequest, but the underlying http request is
+// bound to ctx: it is aborted when ctx is canceled or its deadline is exceeded.
+// Transient failures are retried according to c.RetryPolicy.
+func (c *Client) SendRequestWithContext(ctx context.Context, request Request, response interface{}) error {
+	if v := reflect.ValueOf(request); v.Kind() == reflect.Ptr && v.IsNil() {
+		return fmt.Errorf("expected non-nil request")
+	}
+	// Response types only implement commonResponse on pointer receiver.
+	common, ok := response.(commonResponse)
+	if !ok {
+		return fmt.Errorf("expected pointer arg for response, got %T", response)
+	}
+
+	action := request.ActionName()
+	if !actions[action] {
+		return fmt.Errorf("Unknown action %v for request type: %T", action, request)
+	}
+	if v, ok := request.(Validator); ok && !c.SkipValidation {
+		if err := v.Validate(); err != nil {
+			return err
+		}
 	}
 
 	// Make a copy of request so that we are able to set common fields.
@@ -172,68 +254,132 @@ func (c *Client) SendRequest(request interface{}, response interface{}) error {
 	if err != nil {
 		return err
 	}
+	requestCommon, ok := dst.(commonRequest)
+	if !ok {
+		return fmt.Errorf("request type %T doesn't embed RequestCommon", request)
+	}
 
-	// Set request common parameters. Note 'ac1' is the only supported zone, so we set it here.
-	v := reflect.ValueOf(dst).Elem()
-	v.FieldByName("RequestCommon").FieldByName("Token").SetString(c.auth.PublicKey)
-	v.FieldByName("RequestCommon").FieldByName("Project").SetString(c.auth.ProjectId)
-	v.FieldByName("RequestCommon").FieldByName("Zone").SetString(c.zone)
-	t := reflect.TypeOf(request).String()
-	found := false
-	for action := range actions {
-		// Type name contains action, e.g. anchnet.DescribeInstancesRequest contains DescribeInstances.
-		if strings.Contains(t, action) {
-			v.FieldByName("RequestCommon").FieldByName("Action").SetString(action)
-			found = true
+	// Set request common parameters. Credentials are retrieved once per request,
+	// so that token and signature of all attempts match.
+	auth, err := c.credentials.Credentials()
+	if err != nil {
+		return err
+	}
+	*requestCommon.requestCommon() = RequestCommon{
+		Action:  action,
+		Token:   auth.PublicKey,
+		Zone:    c.zone,
+		Project: auth.ProjectId,
+	}
+
+	// Send actual request, retrying transient failures as the retry policy allows.
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		err = c.send(ctx, auth, dst.(Request), attempt, common)
+		if err == nil || !c.RetryPolicy.shouldRetry(action, attempt, err) {
+			break
+		}
+		if c.Metrics != nil {
+			c.Metrics.ObserveRetry(action, err)
+		}
+		if err = c.RetryPolicy.wait(ctx, attempt); err != nil {
 			break
 		}
 	}
-	if found == false {
-		return fmt.Errorf("Unknown request type: %v", t)
+	if c.Metrics != nil {
+		c.Metrics.ObserveRequest(action, time.Since(start), err)
 	}
+	return err
+}
 
-	// Send actual request.
-	resp, err := c.do(dst)
+// send makes a single attempt to send request and decode anchnet response into
+// response, through middlewares of the client.
+func (c *Client) send(ctx context.Context, auth *AuthConfiguration, request Request, attempt int, response commonResponse) (err error) {
+	if c.RateLimiter != nil {
+		var release func(err error)
+		if release, err = c.RateLimiter.acquire(ctx, request.ActionName()); err != nil {
+			return err
+		}
+		defer func() { release(err) }()
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	req, err := c.newHTTPRequest(ctx, auth, body)
 	if err != nil {
 		return err
 	}
+	call := &Call{
+		Action:      request.ActionName(),
+		Request:     request,
+		Attempt:     attempt,
+		HTTPRequest: req,
+		RequestBody: body,
+		Response:    response,
+	}
+	middlewares := c.middlewares
+	if c.Logger != nil {
+		// Log innermost, to see what is actually sent.
+		middlewares = append(middlewares[:len(middlewares):len(middlewares)], logMiddleware(c.Logger))
+	}
+	return chain(middlewares, c.roundTrip)(call)
+}
+
+// roundTrip is the innermost Handler: it sends the http request of call and
+// decodes anchnet response.
+func (c *Client) roundTrip(call *Call) error {
+	resp, err := c.HTTPClient.Do(call.HTTPRequest)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	call.HTTPResponse = resp
 
-	// Read response and unmarshal it.
+	// Read response and unmarshal it. Clear response first, it may carry fields
+	// from a previous attempt.
 	respBody, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return err
 	}
+	call.ResponseBody = respBody
 
+	response := call.Response.(commonResponse)
+	v := reflect.ValueOf(response).Elem()
+	v.Set(reflect.Zero(v.Type()))
 	err = json.Unmarshal(respBody, response)
 	if err != nil {
+		if resp.StatusCode >= http.StatusBadRequest {
+			return &APIError{Action: call.Action, Message: http.StatusText(resp.StatusCode), HTTPStatus: resp.StatusCode}
+		}
 		return err
 	}
 
 	// Determine error code and set error response accordingly.
-	vv := reflect.ValueOf(response).Elem()
-	code := vv.FieldByName("ResponseCommon").FieldByName("Code").Int()
-	if code != 0 {
-		message := vv.FieldByName("ResponseCommon").FieldByName("Message").String()
-		return fmt.Errorf("Server returns error code %v: %s", code, message)
+	if rc := response.responseCommon(); rc.Code != 0 {
+		return &APIError{
+			Action:     call.Action,
+			Code:       rc.Code,
+			RetCode:    rc.RetCode,
+			Message:    rc.Message,
+			HTTPStatus: resp.StatusCode,
+		}
 	}
 
 	return nil
 }
 
-func (c *Client) do(data interface{}) (resp *http.Response, err error) {
-	buf, err := json.Marshal(data)
-	if err != nil {
-		return nil, err
-	}
-
+// newHTTPRequest creates the signed http request sending json body to anchnet.
+func (c *Client) newHTTPRequest(ctx context.Context, auth *AuthConfiguration, buf []byte) (*http.Request, error) {
 	// All anchnet request uses POST.
-	req, err := http.NewRequest("POST", c.endpoint, bytes.NewBuffer(buf))
+	req, err := http.NewRequestWithContext(ctx, "POST", c.endpoint, bytes.NewBuffer(buf))
 	if err != nil {
 		return nil, err
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("signature", GenSignature(buf, []byte(c.auth.PrivateKey)))
+	req.Header.Set("signature", GenSignature(buf, []byte(auth.PrivateKey)))
 
-	return c.HTTPClient.Do(req)
+	return req, nil
 }
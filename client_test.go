@@ -4,9 +4,20 @@
 package anchnet
 
 import (
+	"context"
+	"errors"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http"
 	"net/http/httptest"
+	"os"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/caicloud/anchnet-go/testutil"
 )
 
 // TestSendRequest tests c.SendRequest.
@@ -33,7 +44,7 @@ func TestSendRequest(t *testing.T) {
 }
 `)
 
-	testServer := httptest.NewServer(&FakeHandler{t: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
+	testServer := httptest.NewServer(&testutil.FakeHandler{T: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
 	defer testServer.Close()
 
 	c, err := NewClient(testServer.URL, &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"})
@@ -98,3 +109,240 @@ func TestSendRequest(t *testing.T) {
 		}
 	}
 }
+
+// TestTypedMethod tests that typed per-action methods fill common request fields
+// and decode the response.
+func TestTypedMethod(t *testing.T) {
+	expectedJson := RemoveWhitespaces(`
+{
+  "instances": ["i-G74Q69NJ"],
+  "force": 0,
+  "zone": "ac1",
+  "token": "E5I9QKJF1O2B5PXE68LG",
+  "project": "pro-2OS5S5F6",
+  "action": "StopInstances"
+}
+`)
+
+	fakeResponse := RemoveWhitespaces(`
+{
+  "ret_code": 0,
+  "action": "StopInstancesResponse",
+  "code": 0,
+  "job_id": "job-ZUBILH5I"
+}
+`)
+
+	testServer := httptest.NewServer(&testutil.FakeHandler{T: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
+	defer testServer.Close()
+
+	c, err := NewClient(testServer.URL, &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret", ProjectId: "pro-2OS5S5F6"})
+	if err != nil {
+		t.Errorf("Unexpected non-nil error %v", err)
+	}
+
+	request := &StopInstancesRequest{InstanceIDs: []string{"i-G74Q69NJ"}}
+	response, err := c.StopInstances(context.Background(), request)
+	if err != nil {
+		t.Errorf("Unexpected non-nil error %v", err)
+	}
+	if response.JobID != "job-ZUBILH5I" {
+		t.Errorf("Expected job id job-ZUBILH5I, got %v", response.JobID)
+	}
+	// Common fields are set on a copy, caller's request is left untouched.
+	if request.RequestCommon != (RequestCommon{}) {
+		t.Errorf("Expected request to be unchanged, got %+v", request.RequestCommon)
+	}
+
+	if _, err := c.StopInstances(context.Background(), nil); err == nil {
+		t.Errorf("Expected error sending nil request")
+	}
+}
+
+// TestSendRequestWithContext tests that cancellation and deadlines of the context
+// reach the http layer.
+func TestSendRequestWithContext(t *testing.T) {
+	stop := make(chan struct{})
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Never respond until the test is done, i.e. a stalled anchnet API.
+		select {
+		case <-stop:
+		case <-r.Context().Done():
+		}
+	}))
+	defer testServer.Close()
+	defer close(stop)
+
+	c, err := NewClient(testServer.URL, &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"})
+	if err != nil {
+		t.Errorf("Unexpected non-nil error %v", err)
+	}
+
+	deadline, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	canceled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+
+	tests := []struct {
+		ctx         context.Context
+		expectError error
+	}{
+		{ctx: deadline, expectError: context.DeadlineExceeded},
+		{ctx: canceled, expectError: context.Canceled},
+	}
+
+	for _, test := range tests {
+		var response StopInstancesResponse
+		done := make(chan error, 1)
+		go func() {
+			done <- c.SendRequestWithContext(test.ctx, StopInstancesRequest{InstanceIDs: []string{"i-G74Q69NJ"}}, &response)
+		}()
+		select {
+		case err := <-done:
+			if !errors.Is(err, test.expectError) {
+				t.Errorf("Expected error %v, got %v", test.expectError, err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Errorf("Expected request to be aborted by context")
+		}
+	}
+}
+
+// allRequests contains every request type in the package. New request types
+// must be added here, TestRequestActions fails otherwise.
+var allRequests = []Request{
+	DescribeInstancesRequest{},
+	RunInstancesRequest{},
+	TerminateInstancesRequest{},
+	StartInstancesRequest{},
+	StopInstancesRequest{},
+	RestartInstancesRequest{},
+	ResizeInstancesRequest{},
+	ResetLoginPasswdRequest{},
+	ModifyInstanceAttributesRequest{},
+
+	DescribeEipsRequest{},
+	AllocateEipsRequest{},
+	ReleaseEipsRequest{},
+	AssociateEipRequest{},
+	DissociateEipsRequest{},
+	ChangeEipsBandwidthRequest{},
+
+	DescribeVxnetsRequest{},
+	CreateVxnetsRequest{},
+	DeleteVxnetsRequest{},
+	JoinVxnetRequest{},
+	LeaveVxnetRequest{},
+	ModifyVxnetAttributesRequest{},
+
+	DescribeVolumesRequest{},
+	CreateVolumesRequest{},
+	DeleteVolumesRequest{},
+	AttachVolumesRequest{},
+	DetachVolumesRequest{},
+	ResizeVolumesRequest{},
+	ModifyVolumeAttributesRequest{},
+
+	DescribeLoadBalancersRequest{},
+	CreateLoadBalancerRequest{},
+	DeleteLoadBalancersRequest{},
+	StartLoadBalancersRequest{},
+	StopLoadBalancersRequest{},
+	ModifyLoadBalancerAttributesRequest{},
+	UpdateLoadBalancersRequest{},
+	ResizeLoadBalancersRequest{},
+	AssociateEipsToLoadBalancerRequest{},
+	DissociateEipsFromLoadBalancerRequest{},
+	AddLoadBalancerListenersRequest{},
+	DeleteLoadBalancerListenersRequest{},
+	DescribeLoadBalancerListenersRequest{},
+	ModifyLoadBalancerListenerAttributesRequest{},
+	AddLoadBalancerBackendsRequest{},
+	DeleteLoadBalancerBackendsRequest{},
+	DescribeLoadBalancerBackendsRequest{},
+	ModifyLoadBalancerBackendAttributesRequest{},
+
+	DescribeSecurityGroupsRequest{},
+	CreateSecurityGroupRequest{},
+	DeleteSecurityGroupsRequest{},
+	ApplySecurityGroupRequest{},
+	ModifySecurityGroupAttributesRequest{},
+	DescribeSecurityGroupRulesRequest{},
+	AddSecurityGroupRulesRequest{},
+	DeleteSecurityGroupRulesRequest{},
+	ModifySecurityGroupRuleAttributesRequest{},
+
+	DescribeKeyPairsRequest{},
+	CreateKeyPairRequest{},
+	DeleteKeyPairsRequest{},
+	AttachKeyPairsRequest{},
+	DetachKeyPairsRequest{},
+
+	DescribeJobsRequest{},
+
+	CreateUserProjectRequest{},
+	DescribeProjectsRequest{},
+	DescribeUsersRequest{},
+	TransferRequest{},
+	GetChargeSummaryRequest{},
+
+	CaptureInstanceRequest{},
+	DescribeImageRequest{},
+	GrantImageToUsersRequest{},
+	RevokeImageFromUsersRequest{},
+	DescribeImageUsersRequest{},
+}
+
+// TestRequestActions tests that every request type in the package resolves to
+// exactly one registered action, and no two request types share an action.
+func TestRequestActions(t *testing.T) {
+	// Collect all request types declared in the package.
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, ".", func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		t.Fatalf("Unexpected error parsing package: %v", err)
+	}
+	declared := make(map[string]bool)
+	for _, file := range pkgs["anchnet"].Files {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				if _, ok := typeSpec.Type.(*ast.StructType); !ok {
+					continue
+				}
+				if strings.HasSuffix(typeSpec.Name.Name, "Request") {
+					declared[typeSpec.Name.Name] = true
+				}
+			}
+		}
+	}
+
+	seen := make(map[string]string)
+	for _, request := range allRequests {
+		name := reflect.TypeOf(request).Name()
+		if !declared[name] {
+			t.Errorf("Request type %v is not declared in the package", name)
+		}
+		delete(declared, name)
+		action := request.ActionName()
+		if !actions[action] {
+			t.Errorf("Request type %v resolves to unknown action %v", name, action)
+		}
+		if other, ok := seen[action]; ok {
+			t.Errorf("Request types %v and %v both resolve to action %v", other, name, action)
+		}
+		seen[action] = name
+		if _, ok := reflect.TypeOf(request).FieldByName("RequestCommon"); !ok {
+			t.Errorf("Request type %v doesn't embed RequestCommon", name)
+		}
+	}
+	for name := range declared {
+		t.Errorf("Request type %v is missing from allRequests", name)
+	}
+}
@@ -4,6 +4,10 @@
 
 package anchnet
 
+import (
+	"context"
+)
+
 // Implements all anchnet instance related APIs.
 
 //
@@ -18,6 +22,15 @@ type DescribeEipsRequest struct {
 	Limit         int         `json:"limit,omitempty"`
 }
 
+func (DescribeEipsRequest) ActionName() string { return "DescribeEips" }
+
+// DescribeEips sends DescribeEipsRequest to anchnet.
+func (c *Client) DescribeEips(ctx context.Context, request *DescribeEipsRequest) (*DescribeEipsResponse, error) {
+	var response DescribeEipsResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type DescribeEipsResponse struct {
 	ResponseCommon `json:",inline"`
 	TotalCount     int                `json:"total_count,omitempty"`
@@ -31,8 +44,8 @@ type DescribeEipsItem struct {
 	Attachon     int                      `json:"attachon,omitempty"`
 	Bandwidth    int                      `json:"bandwidth,omitempty"`
 	Description  string                   `json:"description,omitempty"`
-	CreateTime   string                   `json:"create_time,omitempty"`
-	StatusTime   string                   `json:"status_time,omitempty"`
+	CreateTime   Time                     `json:"create_time,omitempty"`
+	StatusTime   Time                     `json:"status_time,omitempty"`
 	Status       EipStatus                `json:"status,omitempty"`
 	NeedIcp      int                      `json:"need_icp,omitempty"`
 	Resource     DescribeEipsResource     `json:"resource,omitempty"` // Resource means an instance
@@ -63,6 +76,8 @@ const (
 	EipStatusAvailable  EipStatus = "available"
 	EipStatusAssociated EipStatus = "associated"
 	EipStatusSuspended  EipStatus = "suspended"
+	EipStatusReleased   EipStatus = "released"
+	EipStatusCeased     EipStatus = "ceased"
 )
 
 //
@@ -73,6 +88,15 @@ type AllocateEipsRequest struct {
 	Product       AllocateEipsProduct `json:"product,omitempty"`
 }
 
+func (AllocateEipsRequest) ActionName() string { return "AllocateEips" }
+
+// AllocateEips sends AllocateEipsRequest to anchnet.
+func (c *Client) AllocateEips(ctx context.Context, request *AllocateEipsRequest) (*AllocateEipsResponse, error) {
+	var response AllocateEipsResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type AllocateEipsResponse struct {
 	ResponseCommon `json:",inline"`
 	EipIDs         []string `json:"eips,omitempty"`
@@ -104,6 +128,20 @@ type ReleaseEipsRequest struct {
 	EipIDs        []string `json:"eips,omitempty"`
 }
 
+func (ReleaseEipsRequest) ActionName() string { return "ReleaseEips" }
+
+// Validate implements Validator.
+func (r ReleaseEipsRequest) Validate() error {
+	return requireIDs(r.ActionName(), "EipIDs", r.EipIDs)
+}
+
+// ReleaseEips sends ReleaseEipsRequest to anchnet.
+func (c *Client) ReleaseEips(ctx context.Context, request *ReleaseEipsRequest) (*ReleaseEipsResponse, error) {
+	var response ReleaseEipsResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type ReleaseEipsResponse struct {
 	ResponseCommon `json:",inline"`
 	JobID          string `json:"job_id,omitempty"`
@@ -118,6 +156,23 @@ type AssociateEipRequest struct {
 	InstanceID    string `json:"instance,omitempty"`
 }
 
+func (AssociateEipRequest) ActionName() string { return "AssociateEip" }
+
+// Validate implements Validator.
+func (r AssociateEipRequest) Validate() error {
+	return firstError(
+		requireID(r.ActionName(), "EipID", r.EipID),
+		requireID(r.ActionName(), "InstanceID", r.InstanceID),
+	)
+}
+
+// AssociateEip sends AssociateEipRequest to anchnet.
+func (c *Client) AssociateEip(ctx context.Context, request *AssociateEipRequest) (*AssociateEipResponse, error) {
+	var response AssociateEipResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type AssociateEipResponse struct {
 	ResponseCommon `json:",inline"`
 	JobID          string `json:"job_id,omitempty"`
@@ -131,6 +186,20 @@ type DissociateEipsRequest struct {
 	EipIDs        []string `json:"eips,omitempty"`
 }
 
+func (DissociateEipsRequest) ActionName() string { return "DissociateEips" }
+
+// Validate implements Validator.
+func (r DissociateEipsRequest) Validate() error {
+	return requireIDs(r.ActionName(), "EipIDs", r.EipIDs)
+}
+
+// DissociateEips sends DissociateEipsRequest to anchnet.
+func (c *Client) DissociateEips(ctx context.Context, request *DissociateEipsRequest) (*DissociateEipsResponse, error) {
+	var response DissociateEipsResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type DissociateEipsResponse struct {
 	ResponseCommon `json:",inline"`
 	JobID          string `json:"job_id,omitempty"`
@@ -145,7 +214,80 @@ type ChangeEipsBandwidthRequest struct {
 	Bandwidth     int      `json:"bandwidth,omitempty"` // In Mbps
 }
 
+func (ChangeEipsBandwidthRequest) ActionName() string { return "ChangeEipsBandwidth" }
+
+// Validate implements Validator.
+func (r ChangeEipsBandwidthRequest) Validate() error {
+	err := requireIDs(r.ActionName(), "EipIDs", r.EipIDs)
+	if err == nil && r.Bandwidth <= 0 {
+		err = invalid(r.ActionName(), "Bandwidth", "must be positive, got %v", r.Bandwidth)
+	}
+	return err
+}
+
+// ChangeEipsBandwidth sends ChangeEipsBandwidthRequest to anchnet.
+func (c *Client) ChangeEipsBandwidth(ctx context.Context, request *ChangeEipsBandwidthRequest) (*ChangeEipsBandwidthResponse, error) {
+	var response ChangeEipsBandwidthResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type ChangeEipsBandwidthResponse struct {
 	ResponseCommon `json:",inline"`
 	JobID          string `json:"job_id,omitempty"`
 }
+
+// WaitEipStatus waits until all eips reach status, e.g. associated, listing them
+// with ListAllEips on each poll. It returns a *StatusError as soon as any of them
+// becomes suspended, released or ceased instead, or is not found. opts can be nil.
+func (c *Client) WaitEipStatus(ctx context.Context, ids []string, status EipStatus, opts *WaitOptions) error {
+	terminal := []string{string(EipStatusSuspended), string(EipStatusReleased), string(EipStatusCeased)}
+	return waitStatus(ctx, ids, string(status), terminal, opts, func(ctx context.Context, ids []string) (map[string]string, error) {
+		items, err := c.ListAllEips(ctx, DescribeEipsRequest{EipIDs: ids})
+		if err != nil {
+			return nil, err
+		}
+		statuses := make(map[string]string)
+		for _, item := range items {
+			statuses[item.EipID] = string(item.Status)
+		}
+		return statuses, nil
+	})
+}
+
+// EipPager pages through DescribeEips results.
+type EipPager struct {
+	pager
+	page []DescribeEipsItem
+}
+
+// NewEipPager creates a pager for eips matching filter.
+func (c *Client) NewEipPager(filter DescribeEipsRequest) *EipPager {
+	p := &EipPager{}
+	p.pager = newPager(filter.Offset, filter.Limit, func(ctx context.Context, offset, limit int) (int, int, error) {
+		filter.Offset, filter.Limit = offset, limit
+		p.page = nil
+		response, err := c.DescribeEips(ctx, &filter)
+		if err != nil {
+			return 0, 0, err
+		}
+		p.page = response.ItemSet
+		return len(response.ItemSet), response.TotalCount, nil
+	})
+	return p
+}
+
+// Page returns eips in the page fetched by the last call to Next.
+func (p *EipPager) Page() []DescribeEipsItem {
+	return p.page
+}
+
+// ListAllEips returns all eips matching filter, following all pages.
+func (c *Client) ListAllEips(ctx context.Context, filter DescribeEipsRequest) ([]DescribeEipsItem, error) {
+	var items []DescribeEipsItem
+	p := c.NewEipPager(filter)
+	for p.Next(ctx) {
+		items = append(items, p.Page()...)
+	}
+	return items, p.Err()
+}
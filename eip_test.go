@@ -8,6 +8,9 @@ import (
 	"net/http/httptest"
 	"reflect"
 	"testing"
+	"time"
+
+	"github.com/caicloud/anchnet-go/testutil"
 )
 
 // TestDescribeEips tests that we send correct request to describe eips.
@@ -23,7 +26,7 @@ func TestDescribeEips(t *testing.T) {
 }
 `)
 
-	fakeResponse := RemoveWhitespaces(`
+	fakeResponse := `
 {
   "ret_code":0,
   "action":"DescribeEipsResponse",
@@ -37,8 +40,8 @@ func TestDescribeEips(t *testing.T) {
     "need_icp":0,
     "description":"",
     "status":"associated",
-    "status_time":"2015-02-27-12:58:41",
-    "create_time":"2015-02-27-12:52:37",
+    "status_time":"2015-02-27 12:58:41",
+    "create_time":"2015-02-27 12:52:37",
     "eip_group":{
       "eip_group_id":"eipg-00000000",
       "eip_group_name":"BGP multi-line"
@@ -51,9 +54,9 @@ func TestDescribeEips(t *testing.T) {
   "code":0,
   "total_count":1
 }
-`)
+`
 
-	testServer := httptest.NewServer(&FakeHandler{t: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
+	testServer := httptest.NewServer(&testutil.FakeHandler{T: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
 	defer testServer.Close()
 
 	c, err := NewClient(testServer.URL, &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"})
@@ -91,11 +94,11 @@ func TestDescribeEips(t *testing.T) {
 				NeedIcp:     0,
 				Description: "",
 				Status:      EipStatusAssociated,
-				StatusTime:  "2015-02-27-12:58:41",
-				CreateTime:  "2015-02-27-12:52:37",
+				StatusTime:  Time{time.Date(2015, 2, 27, 12, 58, 41, 0, location)},
+				CreateTime:  Time{time.Date(2015, 2, 27, 12, 52, 37, 0, location)},
 				EipGroup: DescribeEipsEipGroup{
 					EipGroupID:   "eipg-00000000",
-					EipGroupName: "BGPmulti-line",
+					EipGroupName: "BGP multi-line",
 				},
 				Resource: DescribeEipsResource{
 					ResourceID:   "i-7QAQCZ2E",
@@ -139,7 +142,7 @@ func TestAllocateEips(t *testing.T) {
 }
 `)
 
-	testServer := httptest.NewServer(&FakeHandler{t: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
+	testServer := httptest.NewServer(&testutil.FakeHandler{T: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
 	defer testServer.Close()
 
 	c, err := NewClient(testServer.URL, &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"})
@@ -199,7 +202,7 @@ func TestReleaseEips(t *testing.T) {
 }
 `)
 
-	testServer := httptest.NewServer(&FakeHandler{t: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
+	testServer := httptest.NewServer(&testutil.FakeHandler{T: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
 	defer testServer.Close()
 
 	c, err := NewClient(testServer.URL, &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"})
@@ -251,7 +254,7 @@ func TestAssociateEip(t *testing.T) {
 }
 `)
 
-	testServer := httptest.NewServer(&FakeHandler{t: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
+	testServer := httptest.NewServer(&testutil.FakeHandler{T: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
 	defer testServer.Close()
 
 	c, err := NewClient(testServer.URL, &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"})
@@ -305,7 +308,7 @@ func TestDissociateEips(t *testing.T) {
 }
 `)
 
-	testServer := httptest.NewServer(&FakeHandler{t: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
+	testServer := httptest.NewServer(&testutil.FakeHandler{T: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
 	defer testServer.Close()
 
 	c, err := NewClient(testServer.URL, &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"})
@@ -357,7 +360,7 @@ func TestChangeEipsBandwidth(t *testing.T) {
 }
 `)
 
-	testServer := httptest.NewServer(&FakeHandler{t: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
+	testServer := httptest.NewServer(&testutil.FakeHandler{T: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
 	defer testServer.Close()
 
 	c, err := NewClient(testServer.URL, &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"})
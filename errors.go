@@ -0,0 +1,112 @@
+// Copyright 2015 anchnet-go authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package anchnet
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Error codes returned by anchnet in ResponseCommon.Code. Anchnet doesn't publish
+// a complete list; following are the codes we have seen in responses:
+//
+//   Code  Meaning
+//   1100  Invalid or missing request parameter
+//   1200  Authentication failure, i.e. bad token or signature
+//   1300  Request expired
+//   1400  Permission denied, e.g. accessing resources of another account, or
+//         acting on a resource in the wrong status, like starting a running instance
+//   2100  Resource not found
+//   2400  Insufficient balance
+//   2500  Quota exceeded, e.g. too many instances or eips in the zone
+//   5000  Internal server error
+//   5100  Server busy, try again later
+//   5200  Resource deficiency, i.e. the zone is out of capacity for the request
+//   5300  Service under maintenance
+const (
+	ErrorCodeInvalidParameter    = 1100
+	ErrorCodeAuthFailure         = 1200
+	ErrorCodeRequestExpired      = 1300
+	ErrorCodePermissionDenied    = 1400
+	ErrorCodeResourceNotFound    = 2100
+	ErrorCodeInsufficientBalance = 2400
+	ErrorCodeQuotaExceeded       = 2500
+	ErrorCodeInternalError       = 5000
+	ErrorCodeServerBusy          = 5100
+	ErrorCodeResourceDeficiency  = 5200
+	ErrorCodeMaintenance         = 5300
+)
+
+// APIError is returned when anchnet rejects a request, either with a nonzero code
+// in the response, or with an http error status and an undecodable body (Code is
+// 0 in the latter case).
+type APIError struct {
+	Action     string // Action of the request, e.g. RunInstances
+	Code       int    // ResponseCommon.Code, see ErrorCode* above
+	RetCode    int    // ResponseCommon.RetCode
+	Message    string // ResponseCommon.Message, or http status text
+	HTTPStatus int    // Status code of the http response
+}
+
+func (e *APIError) Error() string {
+	if e.Code == 0 {
+		return fmt.Sprintf("Server returns http status %v for %v: %s", e.HTTPStatus, e.Action, e.Message)
+	}
+	return fmt.Sprintf("Server returns error code %v for %v: %s", e.Code, e.Action, e.Message)
+}
+
+// IsAPIError returns the APIError in err's chain, if any.
+func IsAPIError(err error) (*APIError, bool) {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr, true
+	}
+	return nil, false
+}
+
+// IsNotFound returns true if err means the requested resource doesn't exist.
+func IsNotFound(err error) bool {
+	return hasErrorCode(err, ErrorCodeResourceNotFound)
+}
+
+// IsQuotaExceeded returns true if err means a resource quota is used up.
+func IsQuotaExceeded(err error) bool {
+	return hasErrorCode(err, ErrorCodeQuotaExceeded)
+}
+
+// IsInsufficientBalance returns true if err means the account is out of money.
+func IsInsufficientBalance(err error) bool {
+	return hasErrorCode(err, ErrorCodeInsufficientBalance)
+}
+
+// IsAuthFailure returns true if err means the keys or signature are rejected.
+func IsAuthFailure(err error) bool {
+	return hasErrorCode(err, ErrorCodeAuthFailure)
+}
+
+// IsPermissionDenied returns true if err means the action isn't allowed on the
+// resource, e.g. because the resource is in the wrong status.
+func IsPermissionDenied(err error) bool {
+	return hasErrorCode(err, ErrorCodePermissionDenied)
+}
+
+// IsResourceDeficiency returns true if err means the zone lacks capacity for the
+// requested resources.
+func IsResourceDeficiency(err error) bool {
+	return hasErrorCode(err, ErrorCodeResourceDeficiency)
+}
+
+// IsThrottled returns true if err means anchnet is rejecting requests because
+// too many are sent: server busy code, or http 429.
+func IsThrottled(err error) bool {
+	apiErr, ok := IsAPIError(err)
+	return ok && (apiErr.Code == ErrorCodeServerBusy || apiErr.HTTPStatus == http.StatusTooManyRequests)
+}
+
+func hasErrorCode(err error, code int) bool {
+	apiErr, ok := IsAPIError(err)
+	return ok && apiErr.Code == code
+}
@@ -0,0 +1,87 @@
+// Copyright 2015 anchnet-go authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package anchnet
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// TestAPIError tests that anchnet errors are returned as APIError.
+func TestAPIError(t *testing.T) {
+	tests := []struct {
+		status          int
+		body            string
+		expectedError   *APIError
+		isNotFound      bool
+		isQuotaExceeded bool
+	}{
+		{
+			status: http.StatusOK,
+			body:   `{"ret_code": 2100, "action": "DescribeInstancesResponse", "code": 2100, "message": "resource not found"}`,
+			expectedError: &APIError{
+				Action:     "DescribeInstances",
+				Code:       2100,
+				RetCode:    2100,
+				Message:    "resource not found",
+				HTTPStatus: http.StatusOK,
+			},
+			isNotFound: true,
+		},
+		{
+			status: http.StatusOK,
+			body:   `{"ret_code": 2500, "action": "DescribeInstancesResponse", "code": 2500, "message": "quota exceeded"}`,
+			expectedError: &APIError{
+				Action:     "DescribeInstances",
+				Code:       2500,
+				RetCode:    2500,
+				Message:    "quota exceeded",
+				HTTPStatus: http.StatusOK,
+			},
+			isQuotaExceeded: true,
+		},
+		{
+			status: http.StatusServiceUnavailable,
+			body:   `<html>Service Unavailable</html>`,
+			expectedError: &APIError{
+				Action:     "DescribeInstances",
+				Message:    "Service Unavailable",
+				HTTPStatus: http.StatusServiceUnavailable,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(test.status)
+			w.Write([]byte(test.body))
+		}))
+
+		c, err := NewClient(testServer.URL, &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"})
+		if err != nil {
+			t.Errorf("Unexpected non-nil error %v", err)
+		}
+		c.RetryPolicy = NoRetry
+
+		var response DescribeInstancesResponse
+		err = c.SendRequest(DescribeInstancesRequest{InstanceIDs: []string{"i-HNFNPM56"}}, &response)
+		apiErr, ok := IsAPIError(fmt.Errorf("wrapped: %w", err))
+		if !ok {
+			t.Errorf("Expected APIError, got %v", err)
+		} else if !reflect.DeepEqual(test.expectedError, apiErr) {
+			t.Errorf("Error: expected \n%+v, got \n%+v", test.expectedError, apiErr)
+		}
+		if IsNotFound(err) != test.isNotFound {
+			t.Errorf("Expected IsNotFound %v for %v", test.isNotFound, err)
+		}
+		if IsQuotaExceeded(err) != test.isQuotaExceeded {
+			t.Errorf("Expected IsQuotaExceeded %v for %v", test.isQuotaExceeded, err)
+		}
+		testServer.Close()
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2015 anchnet-go authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package anchnet
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/caicloud/anchnet-go/replay"
+	"github.com/caicloud/anchnet-go/testutil"
+)
+
+// fixtureTypes maps actions of recorded fixtures to their request and response types.
+var fixtureTypes = map[string]struct {
+	request  Request
+	response interface{}
+}{
+	"DescribeInstances":             {DescribeInstancesRequest{}, DescribeInstancesResponse{}},
+	"DescribeVxnets":                {DescribeVxnetsRequest{}, DescribeVxnetsResponse{}},
+	"DescribeLoadBalancers":         {DescribeLoadBalancersRequest{}, DescribeLoadBalancersResponse{}},
+	"DescribeLoadBalancerListeners": {DescribeLoadBalancerListenersRequest{}, DescribeLoadBalancerListenersResponse{}},
+	"DescribeLoadBalancerBackends":  {DescribeLoadBalancerBackendsRequest{}, DescribeLoadBalancerBackendsResponse{}},
+	"DescribeSecurityGroups":        {DescribeSecurityGroupsRequest{}, DescribeSecurityGroupsResponse{}},
+	"DescribeSecurityGroupRules":    {DescribeSecurityGroupRulesRequest{}, DescribeSecurityGroupRulesResponse{}},
+}
+
+// TestFixtures tests that anchnet traffic recorded under testdata/replay (see package
+// replay) fits our request and response types, and plays back through Client.
+// Response fields missing from our types are reported, e.g. when fixtures show the
+// content of DescribeVxnetsRouter.
+func TestFixtures(t *testing.T) {
+	dir := filepath.Join("testdata", "replay")
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("Expected fixtures, got %v, %v", paths, err)
+	}
+
+	c, err := NewClient("http://anchnet.test", &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"})
+	if err != nil {
+		t.Fatalf("Unexpected non-nil error %v", err)
+	}
+	c.RetryPolicy = NoRetry
+	c.HTTPClient = &http.Client{Transport: &replay.Transport{Dir: dir, Mode: replay.Replay, MatchRequest: true}}
+
+	for _, path := range paths {
+		interaction, err := replay.Load(path)
+		if err != nil {
+			t.Errorf("Unexpected non-nil error %v", err)
+			continue
+		}
+		var common RequestCommon
+		json.Unmarshal(interaction.Request, &common)
+		types, ok := fixtureTypes[common.Action]
+		if !ok {
+			t.Errorf("%v: no types registered for action %v", path, common.Action)
+			continue
+		}
+
+		request := reflect.New(reflect.TypeOf(types.request)).Interface().(Request)
+		if err := json.Unmarshal(interaction.Request, request); err != nil {
+			t.Errorf("%v: unexpected error decoding request: %v", path, err)
+			continue
+		}
+		buf, _ := json.Marshal(request)
+		testutil.AssertJSONEqual(t, string(interaction.Request), string(buf))
+
+		expected := reflect.New(reflect.TypeOf(types.response)).Interface()
+		decoder := json.NewDecoder(bytes.NewReader(interaction.Response))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(expected); err != nil {
+			t.Errorf("%v: response doesn't fit %T: %v", path, expected, err)
+			continue
+		}
+
+		// Token is set by client again, and redacted when matching the recording.
+		request.(commonRequest).requestCommon().Token = ""
+		response := reflect.New(reflect.TypeOf(types.response)).Interface()
+		if err := c.SendRequest(request, response); err != nil {
+			t.Errorf("%v: unexpected error playing back: %v", path, err)
+			continue
+		}
+		if !reflect.DeepEqual(expected, response) {
+			t.Errorf("%v: expected played back response \n%+v, got \n%+v", path, expected, response)
+		}
+	}
+}
@@ -4,6 +4,10 @@
 
 package anchnet
 
+import (
+	"context"
+)
+
 //
 // CaptureInstance creates an image from a stopped instance.
 //
@@ -13,11 +17,75 @@ type CaptureInstanceRequest struct {
 	Instance      string `json:"instance,omitempty"`
 }
 
+func (CaptureInstanceRequest) ActionName() string { return "CaptureInstance" }
+
+// Validate implements Validator.
+func (r CaptureInstanceRequest) Validate() error {
+	return requireID(r.ActionName(), "Instance", r.Instance)
+}
+
+// CaptureInstance sends CaptureInstanceRequest to anchnet.
+func (c *Client) CaptureInstance(ctx context.Context, request *CaptureInstanceRequest) (*CaptureInstanceResponse, error) {
+	var response CaptureInstanceResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type CaptureInstanceResponse struct {
 	ResponseCommon `json:",inline"`
 	ImageID        string `json:"image_id,omitempty"`
 }
 
+//
+// DescribeImage describes images, either system images or images captured by
+// the user.
+//
+type DescribeImageRequest struct {
+	RequestCommon `json:",inline"`
+	ImageIDs      []string      `json:"images,omitempty"`
+	Provider      ImageProvider `json:"provider,omitempty"`
+	SearchWord    string        `json:"search_word,omitempty"`
+	Verbose       int           `json:"verbose,omitempty"`
+	Offset        int           `json:"offset,omitempty"`
+	Limit         int           `json:"limit,omitempty"`
+}
+
+func (DescribeImageRequest) ActionName() string { return "DescribeImage" }
+
+// Validate implements Validator.
+func (r DescribeImageRequest) Validate() error {
+	if r.Provider == "" {
+		return nil
+	}
+	return requireOneOf(r.ActionName(), "Provider", r.Provider, ImageProviderSelf, ImageProviderSystem)
+}
+
+// DescribeImage sends DescribeImageRequest to anchnet.
+func (c *Client) DescribeImage(ctx context.Context, request *DescribeImageRequest) (*DescribeImageResponse, error) {
+	var response DescribeImageResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
+type DescribeImageResponse struct {
+	ResponseCommon `json:",inline"`
+	ItemSet        []DescribeImageItem `json:"item_set,omitempty"`
+	TotalCount     int                 `json:"total_count,omitempty"`
+}
+
+type DescribeImageItem struct {
+	ImageID       string             `json:"image_id,omitempty"`
+	ImageName     string             `json:"image_name,omitempty"`
+	Description   string             `json:"description,omitempty"`
+	Size          Int                `json:"size,omitempty"` // Unit: GB
+	Status        string             `json:"status,omitempty"`
+	OsFamily      ImageOsFamily      `json:"os_family,omitempty"`
+	Platform      ImagePlatform      `json:"platform,omitempty"`
+	ProcessorType ImageProcessorType `json:"processor_type,omitempty"`
+	Provider      ImageProvider      `json:"provider,omitempty"`
+	CreateTime    Time               `json:"create_time,omitempty"`
+}
+
 //
 // GrantImageToUsers allows users to access an image. This is often used to grant
 // image from main account to sub-accounts.
@@ -28,6 +96,23 @@ type GrantImageToUsersRequest struct {
 	UserIDs       []string `json:"users,omitempty"`
 }
 
+func (GrantImageToUsersRequest) ActionName() string { return "GrantImageToUsers" }
+
+// Validate implements Validator.
+func (r GrantImageToUsersRequest) Validate() error {
+	return firstError(
+		requireID(r.ActionName(), "ImageID", r.ImageID),
+		requireIDs(r.ActionName(), "UserIDs", r.UserIDs),
+	)
+}
+
+// GrantImageToUsers sends GrantImageToUsersRequest to anchnet.
+func (c *Client) GrantImageToUsers(ctx context.Context, request *GrantImageToUsersRequest) (*GrantImageToUsersResponse, error) {
+	var response GrantImageToUsersResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type GrantImageToUsersResponse struct {
 	ResponseCommon `json:",inline"`
 }
@@ -42,6 +127,23 @@ type RevokeImageFromUsersRequest struct {
 	UserIDs  []string `json:"users,omitempty"`
 }
 
+func (RevokeImageFromUsersRequest) ActionName() string { return "RevokeImageFromUsers" }
+
+// Validate implements Validator.
+func (r RevokeImageFromUsersRequest) Validate() error {
+	return firstError(
+		requireIDs(r.ActionName(), "ImageIDs", r.ImageIDs),
+		requireIDs(r.ActionName(), "UserIDs", r.UserIDs),
+	)
+}
+
+// RevokeImageFromUsers sends RevokeImageFromUsersRequest to anchnet.
+func (c *Client) RevokeImageFromUsers(ctx context.Context, request *RevokeImageFromUsersRequest) (*RevokeImageFromUsersResponse, error) {
+	var response RevokeImageFromUsersResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type RevokeImageFromUsersResponse struct {
 	ResponseCommon `json:",inline"`
 }
@@ -57,6 +159,15 @@ type DescribeImageUsersRequest struct {
 	Limit    int      `json:"limit,omitempty"`
 }
 
+func (DescribeImageUsersRequest) ActionName() string { return "DescribeImageUsers" }
+
+// DescribeImageUsers sends DescribeImageUsersRequest to anchnet.
+func (c *Client) DescribeImageUsers(ctx context.Context, request *DescribeImageUsersRequest) (*DescribeImageUsersResponse, error) {
+	var response DescribeImageUsersResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type DescribeImageUsersResponse struct {
 	ResponseCommon `json:",inline"`
 	UserSet        []DescribeImageUsersItem `json:"user_set,omitempty"`
@@ -68,3 +179,40 @@ type DescribeImageUsersItem struct {
 	UserID   string `json:"usr_id,omitempty"`
 	Username string `json:"username,omitempty"`
 }
+
+// ImageUserPager pages through DescribeImageUsers results.
+type ImageUserPager struct {
+	pager
+	page []DescribeImageUsersItem
+}
+
+// NewImageUserPager creates a pager for image users matching filter.
+func (c *Client) NewImageUserPager(filter DescribeImageUsersRequest) *ImageUserPager {
+	p := &ImageUserPager{}
+	p.pager = newPager(filter.Offset, filter.Limit, func(ctx context.Context, offset, limit int) (int, int, error) {
+		filter.Offset, filter.Limit = offset, limit
+		p.page = nil
+		response, err := c.DescribeImageUsers(ctx, &filter)
+		if err != nil {
+			return 0, 0, err
+		}
+		p.page = response.UserSet
+		return len(response.UserSet), response.TotalCount, nil
+	})
+	return p
+}
+
+// Page returns image users in the page fetched by the last call to Next.
+func (p *ImageUserPager) Page() []DescribeImageUsersItem {
+	return p.page
+}
+
+// ListAllImageUsers returns all image users matching filter, following all pages.
+func (c *Client) ListAllImageUsers(ctx context.Context, filter DescribeImageUsersRequest) ([]DescribeImageUsersItem, error) {
+	var items []DescribeImageUsersItem
+	p := c.NewImageUserPager(filter)
+	for p.Next(ctx) {
+		items = append(items, p.Page()...)
+	}
+	return items, p.Err()
+}
@@ -0,0 +1,96 @@
+// Copyright 2015 anchnet-go authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package anchnet
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/caicloud/anchnet-go/testutil"
+)
+
+// TestDescribeImage tests that we send correct request to describe images.
+func TestDescribeImage(t *testing.T) {
+	expectedJson := RemoveWhitespaces(`
+{
+  "images": [
+    "trustysrvx64c"
+  ],
+  "provider": "system",
+  "token": "E5I9QKJF1O2B5PXE68LG",
+  "action": "DescribeImage",
+  "zone": "ac1"
+}
+`)
+
+	fakeResponse := `
+{
+  "ret_code": 0,
+  "action": "DescribeImageResponse",
+  "item_set": [
+    {
+      "image_id": "trustysrvx64c",
+      "image_name": "Ubuntu 14.04 64bit",
+      "description": "",
+      "size": "20",
+      "status": "available",
+      "os_family": "ubuntu",
+      "platform": "linux",
+      "processor_type": "64bit",
+      "provider": "system",
+      "create_time": "2015-04-23 10:12:41"
+    }
+  ],
+  "code": 0,
+  "total_count": 1
+}
+`
+
+	testServer := httptest.NewServer(&testutil.FakeHandler{T: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
+	defer testServer.Close()
+
+	c, err := NewClient(testServer.URL, &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"})
+	if err != nil {
+		t.Errorf("Unexpected non-nil error %v", err)
+	}
+
+	request := DescribeImageRequest{
+		ImageIDs: []string{"trustysrvx64c"},
+		Provider: ImageProviderSystem,
+	}
+	var response DescribeImageResponse
+
+	err = c.SendRequest(request, &response)
+	if err != nil {
+		t.Errorf("Unexpected non-nil error %v", err)
+	}
+
+	expectedResponse := DescribeImageResponse{
+		ResponseCommon: ResponseCommon{
+			Action:  "DescribeImageResponse",
+			Code:    0,
+			RetCode: 0,
+		},
+		TotalCount: 1,
+		ItemSet: []DescribeImageItem{
+			{
+				ImageID:       "trustysrvx64c",
+				ImageName:     "Ubuntu 14.04 64bit",
+				Size:          20,
+				Status:        "available",
+				OsFamily:      ImageOsFamilyUbuntu,
+				Platform:      ImagePlatformLinux,
+				ProcessorType: InstanceProcessor64bit,
+				Provider:      ImageProviderSystem,
+				CreateTime:    Time{time.Date(2015, 4, 23, 10, 12, 41, 0, location)},
+			},
+		},
+	}
+	if !reflect.DeepEqual(expectedResponse, response) {
+		t.Errorf("Error: expected \n%v, got \n%v", expectedResponse, response)
+	}
+}
@@ -4,6 +4,11 @@
 
 package anchnet
 
+import (
+	"context"
+	"fmt"
+)
+
 // Implements all anchnet instance related APIs.
 
 //
@@ -19,6 +24,15 @@ type DescribeInstancesRequest struct {
 	Limit         int              `json:"limit,omitempty"`
 }
 
+func (DescribeInstancesRequest) ActionName() string { return "DescribeInstances" }
+
+// DescribeInstances sends DescribeInstancesRequest to anchnet.
+func (c *Client) DescribeInstances(ctx context.Context, request *DescribeInstancesRequest) (*DescribeInstancesResponse, error) {
+	var response DescribeInstancesResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type DescribeInstancesResponse struct {
 	ResponseCommon `json:",inline"`
 	TotalCount     int                     `json:"total_count,omitempty"`
@@ -26,20 +40,22 @@ type DescribeInstancesResponse struct {
 }
 
 type DescribeInstancesItem struct {
-	InstanceID    string                         `json:"instance_id,omitempty"`
-	InstanceName  string                         `json:"instance_name,omitempty"`
-	Description   string                         `json:"description,omitempty"`
-	VcpusCurrent  int                            `json:"vcpus_current,omitempty"`  // Number of CPU cores
-	MemoryCurrent int                            `json:"memory_current,omitempty"` // Memory size, unit: MB
-	Status        InstanceStatus                 `json:"status,omitempty"`         // Status of the instance
-	StatusTime    string                         `json:"status_time,omitempty"`    // Last date when instance was changed
-	CreateTime    string                         `json:"create_time,omitempty"`    // Date when instance was created
-	Vxnets        []DescribeInstancesVxnet       `json:"vxnets,omitempty"`         // SDN network information of the instance
-	EIP           DescribeInstancesEIP           `json:"eip,omitempty"`            // External IP information of the ip
-	Image         DescribeInstancesImage         `json:"image,omitempty"`          // Image information of the instance
-	Volumes       []DescribeInstancesVolume      `json:"volumes,omitempty"`        // Volume information of the instance
-	SecurityGroup DescribeInstancesSecurityGroup `json:"security_group,omitempty"` // Security group (firewall) information of the instance
-	VolumeIDs     []string                       `json:"volume_ids,omitempty"`     // Volume IDs, maybe duplicate with Volumes
+	InstanceID       string                         `json:"instance_id,omitempty"`
+	InstanceName     string                         `json:"instance_name,omitempty"`
+	Description      string                         `json:"description,omitempty"`
+	VcpusCurrent     int                            `json:"vcpus_current,omitempty"`     // Number of CPU cores
+	MemoryCurrent    int                            `json:"memory_current,omitempty"`    // Memory size, unit: MB
+	Status           InstanceStatus                 `json:"status,omitempty"`            // Status of the instance
+	TransitionStatus string                         `json:"transition_status,omitempty"` // Ongoing change of the instance, e.g. starting
+	StatusTime       Time                           `json:"status_time,omitempty"`       // Last date when instance was changed
+	CreateTime       Time                           `json:"create_time,omitempty"`       // Date when instance was created
+	Vxnets           []DescribeInstancesVxnet       `json:"vxnets,omitempty"`            // SDN network information of the instance
+	EIP              DescribeInstancesEIP           `json:"eip,omitempty"`               // External IP information of the ip
+	Image            DescribeInstancesImage         `json:"image,omitempty"`             // Image information of the instance
+	Volumes          []DescribeInstancesVolume      `json:"volumes,omitempty"`           // Volume information of the instance
+	SecurityGroup    DescribeInstancesSecurityGroup `json:"security_group,omitempty"`    // Security group (firewall) information of the instance
+	VolumeIDs        []string                       `json:"volume_ids,omitempty"`        // Volume IDs, maybe duplicate with Volumes
+	KeyPairIDs       []string                       `json:"keypair_ids,omitempty"`       // Keypairs attached to the instance
 }
 
 type DescribeInstancesVxnet struct {
@@ -68,12 +84,10 @@ type DescribeInstancesImage struct {
 }
 
 type DescribeInstancesVolume struct {
-	// Note Size and VolumeType is string instead of int, sxxk. They are integers
-	// when using DescribeVolumes.
-	Size       string `json:"size,omitempty"`
-	VolumeID   string `json:"volume_id,omitempty"`
-	VolumeName string `json:"volume_name,omitempty"`
-	VolumeType string `json:"volume_type,omitempty"`
+	Size       Int        `json:"size,omitempty"` // Unit: GB
+	VolumeID   string     `json:"volume_id,omitempty"`
+	VolumeName string     `json:"volume_name,omitempty"`
+	VolumeType VolumeType `json:"volume_type,omitempty"`
 }
 
 type DescribeInstancesSecurityGroup struct {
@@ -86,10 +100,12 @@ type DescribeInstancesSecurityGroup struct {
 type InstanceStatus string
 
 const (
-	InstanceStatusPending   InstanceStatus = "pending"
-	InstanceStatusRunning   InstanceStatus = "running"
-	InstanceStatusStopped   InstanceStatus = "stopped"
-	InstanceStatusSuspended InstanceStatus = "suspended"
+	InstanceStatusPending    InstanceStatus = "pending"
+	InstanceStatusRunning    InstanceStatus = "running"
+	InstanceStatusStopped    InstanceStatus = "stopped"
+	InstanceStatusSuspended  InstanceStatus = "suspended"
+	InstanceStatusTerminated InstanceStatus = "terminated"
+	InstanceStatusCeased     InstanceStatus = "ceased"
 )
 
 type ImagePlatform string
@@ -130,6 +146,67 @@ type RunInstancesRequest struct {
 	Product       RunInstancesProduct `json:"product,omitempty"`
 }
 
+func (RunInstancesRequest) ActionName() string { return "RunInstances" }
+
+// Validate implements Validator.
+func (r RunInstancesRequest) Validate() error {
+	a, cloud := r.ActionName(), r.Product.Cloud
+	err := firstError(
+		requireID(a, "Product.Cloud.VM.ImageID", cloud.VM.ImageID),
+		requireOneOf(a, "Product.Cloud.VM.Mem", cloud.VM.Mem, memChoices...),
+		requireOneOf(a, "Product.Cloud.VM.Cpu", cloud.VM.Cpu, cpuChoices...),
+		requireOneOf(a, "Product.Cloud.VM.LoginMode", cloud.VM.LoginMode, LoginModePwd, LoginModeKeypair),
+	)
+	if err != nil {
+		return err
+	}
+	switch {
+	case cloud.VM.LoginMode == LoginModePwd && cloud.VM.Password == "":
+		return invalid(a, "Product.Cloud.VM.Password", "is required in login mode %v", LoginModePwd)
+	case cloud.VM.LoginMode == LoginModeKeypair && cloud.VM.KeyPairID == "":
+		return invalid(a, "Product.Cloud.VM.KeyPairID", "is required in login mode %v", LoginModeKeypair)
+	}
+	for i, hd := range cloud.HD {
+		field := fmt.Sprintf("Product.Cloud.HD[%d]", i)
+		if len(hd.HdIDs) > 0 {
+			err = requireIDs(a, field+".HdIDs", hd.HdIDs)
+		} else {
+			err = firstError(
+				requireOneOf(a, field+".Type", hd.Type, HDTypePerformance, HDTypeCapacity),
+				requireRange(a, field+".Unit", hd.Unit, MinVolumeSize, MaxVolumeSize),
+			)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	for i, net := range cloud.Net1 {
+		field := fmt.Sprintf("Product.Cloud.Net1[%d]", i)
+		if len(net.VxnetIDs) > 0 {
+			err = requireIDs(a, field+".VxnetIDs", net.VxnetIDs)
+		} else {
+			err = requireID(a, field+".VxnetName", net.VxnetName)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if (cloud.IP.EipID != "" || cloud.IP.Bandwidth > 0) && !cloud.Net0 {
+		return invalid(a, "Product.Cloud.Net0", "must be set to use IP")
+	}
+	if cloud.IP.Bandwidth < 0 || cloud.Amount < 0 {
+		return invalid(a, "Product.Cloud", "has negative bandwidth or amount")
+	}
+	return nil
+}
+
+// RunInstances sends RunInstancesRequest to anchnet.
+func (c *Client) RunInstances(ctx context.Context, request *RunInstancesRequest) (*RunInstancesResponse, error) {
+	var response RunInstancesResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type RunInstancesResponse struct {
 	ResponseCommon `json:",inline"`
 	InstanceIDs    []string `json:"instances,omitempty"` // IDs of created instances
@@ -166,11 +243,13 @@ type RunInstancesCloud struct {
 	Amount int `json:"amount,omitempty"`
 }
 
-// LoginMode specifies how to login to a machine, only password mode is supported.
+// LoginMode specifies how to login to a machine: with a password, or with an SSH
+// keypair (see CreateKeyPair).
 type LoginMode string
 
 const (
-	LoginModePwd LoginMode = "pwd"
+	LoginModePwd     LoginMode = "pwd"
+	LoginModeKeypair LoginMode = "keypair"
 )
 
 // RunInstancesVM sets parameters for a virtual machine.
@@ -181,8 +260,18 @@ type RunInstancesVM struct {
 	Cpu       int       `json:"cpu,omitempty"`      // Choices: 1,2,4,8 (Number of cores)
 	ImageID   string    `json:"image_id,omitempty"` // Image to use, e.g. opensuse12x64c, trustysrvx64c, etc
 	Password  string    `json:"password,omitempty"` // Used if login mode is password
+	KeyPairID string    `json:"keypair,omitempty"`  // Used if login mode is keypair
+	// UserData is passed to the instance at creation, e.g. a cloud-init script. It
+	// is base64 encoded in the request.
+	UserData []byte `json:"userdata,omitempty"`
 }
 
+// Choices of instance memory (MB) and CPU cores.
+var (
+	memChoices = []interface{}{1024, 2048, 4096, 8192, 16384, 32768}
+	cpuChoices = []interface{}{1, 2, 4, 8}
+)
+
 // HDType is the same as VolumeType.
 type HDType int
 
@@ -191,6 +280,22 @@ const (
 	HDTypeCapacity    HDType = 1
 )
 
+// UnmarshalJSON decodes a disk type, which may also be a string like VolumeType.
+// Unlike VolumeType, HDType can't tell empty string from HDTypePerformance, so
+// empty string is an error rather than read as a performance disk.
+func (t *HDType) UnmarshalJSON(data []byte) error {
+	value, set, err := unmarshalDiskType(data)
+	switch {
+	case err != nil:
+		return err
+	case set:
+		*t = HDType(value)
+	case string(data) == `""`:
+		return fmt.Errorf("anchnet disk type should be a number, got empty string")
+	}
+	return nil
+}
+
 // RunInstancesHardDisk sets parameters for a hard disk.
 type RunInstancesHardDisk struct {
 	// Following fields are used when creating hard disk along with new instance.
@@ -233,6 +338,20 @@ type TerminateInstancesRequest struct {
 	EipIDs        []string `json:"ips,omitempty"`
 }
 
+func (TerminateInstancesRequest) ActionName() string { return "TerminateInstances" }
+
+// Validate implements Validator.
+func (r TerminateInstancesRequest) Validate() error {
+	return requireIDs(r.ActionName(), "InstanceIDs", r.InstanceIDs)
+}
+
+// TerminateInstances sends TerminateInstancesRequest to anchnet.
+func (c *Client) TerminateInstances(ctx context.Context, request *TerminateInstancesRequest) (*TerminateInstancesResponse, error) {
+	var response TerminateInstancesResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type TerminateInstancesResponse struct {
 	ResponseCommon `json:",inline"`
 	JobID          string `json:"job_id,omitempty"` // Job ID in anchnet
@@ -246,8 +365,22 @@ type StartInstancesRequest struct {
 	InstanceIDs   []string `json:"instances,omitempty"`
 }
 
+func (StartInstancesRequest) ActionName() string { return "StartInstances" }
+
+// Validate implements Validator.
+func (r StartInstancesRequest) Validate() error {
+	return requireIDs(r.ActionName(), "InstanceIDs", r.InstanceIDs)
+}
+
+// StartInstances sends StartInstancesRequest to anchnet.
+func (c *Client) StartInstances(ctx context.Context, request *StartInstancesRequest) (*StartInstancesResponse, error) {
+	var response StartInstancesResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type StartInstancesResponse struct {
-	ResponseCommon `json",inline"`
+	ResponseCommon `json:",inline"`
 	JobID          string `json:"job_id,omitempty"`
 }
 
@@ -261,6 +394,23 @@ type StopInstancesRequest struct {
 	Force         InstanceStopType `json:"force"` // Do not omitempty due to NonForceStop=0
 }
 
+func (StopInstancesRequest) ActionName() string { return "StopInstances" }
+
+// Validate implements Validator.
+func (r StopInstancesRequest) Validate() error {
+	return firstError(
+		requireIDs(r.ActionName(), "InstanceIDs", r.InstanceIDs),
+		requireOneOf(r.ActionName(), "Force", r.Force, NonForceStop, ForceStop),
+	)
+}
+
+// StopInstances sends StopInstancesRequest to anchnet.
+func (c *Client) StopInstances(ctx context.Context, request *StopInstancesRequest) (*StopInstancesResponse, error) {
+	var response StopInstancesResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type StopInstancesResponse struct {
 	ResponseCommon `json:",inline"`
 	JobID          string `json:"job_id,omitempty"` // Job ID in anchnet
@@ -282,8 +432,22 @@ type RestartInstancesRequest struct {
 	InstanceIDs   []string `json:"instances,omitempty"`
 }
 
+func (RestartInstancesRequest) ActionName() string { return "RestartInstances" }
+
+// Validate implements Validator.
+func (r RestartInstancesRequest) Validate() error {
+	return requireIDs(r.ActionName(), "InstanceIDs", r.InstanceIDs)
+}
+
+// RestartInstances sends RestartInstancesRequest to anchnet.
+func (c *Client) RestartInstances(ctx context.Context, request *RestartInstancesRequest) (*RestartInstancesResponse, error) {
+	var response RestartInstancesResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type RestartInstancesResponse struct {
-	ResponseCommon `json",inline"`
+	ResponseCommon `json:",inline"`
 	JobID          string `json:"job_id,omitempty"`
 }
 
@@ -297,8 +461,25 @@ type ResetLoginPasswdRequest struct {
 	LoginPasswd   string   `json:"login_passwd,omitempty"`
 }
 
+func (ResetLoginPasswdRequest) ActionName() string { return "ResetLoginPasswd" }
+
+// Validate implements Validator.
+func (r ResetLoginPasswdRequest) Validate() error {
+	return firstError(
+		requireIDs(r.ActionName(), "InstanceIDs", r.InstanceIDs),
+		requireID(r.ActionName(), "LoginPasswd", r.LoginPasswd),
+	)
+}
+
+// ResetLoginPasswd sends ResetLoginPasswdRequest to anchnet.
+func (c *Client) ResetLoginPasswd(ctx context.Context, request *ResetLoginPasswdRequest) (*ResetLoginPasswdResponse, error) {
+	var response ResetLoginPasswdResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type ResetLoginPasswdResponse struct {
-	ResponseCommon `json",inline"`
+	ResponseCommon `json:",inline"`
 	JobID          string `json:"job_id,omitempty"`
 }
 
@@ -312,8 +493,181 @@ type ModifyInstanceAttributesRequest struct {
 	Description   string `json:"description,omitempty"`
 }
 
+func (ModifyInstanceAttributesRequest) ActionName() string { return "ModifyInstanceAttributes" }
+
+// Validate implements Validator.
+func (r ModifyInstanceAttributesRequest) Validate() error {
+	return requireID(r.ActionName(), "InstanceID", r.InstanceID)
+}
+
+// ModifyInstanceAttributes sends ModifyInstanceAttributesRequest to anchnet.
+func (c *Client) ModifyInstanceAttributes(ctx context.Context, request *ModifyInstanceAttributesRequest) (*ModifyInstanceAttributesResponse, error) {
+	var response ModifyInstanceAttributesResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type ModifyInstanceAttributesResponse struct {
 	ResponseCommon `json:",inline"`
 	InstanceID     string `json:"instance_id,omitempty"`
 	JobID          string `json:"job_id,omitempty"`
 }
+
+//
+// ResizeInstancesRequest changes CPU and memory of a list of instances. The
+// instances must be stopped first, see Client.ResizeAndRestartInstances.
+//
+type ResizeInstancesRequest struct {
+	RequestCommon `json:",inline"`
+	InstanceIDs   []string `json:"instances,omitempty"`
+	Cpu           int      `json:"cpu,omitempty"` // Choices: 1,2,4,8 (Number of cores); zero keeps current value
+	Mem           int      `json:"mem,omitempty"` // Choices: 1024,2048,4096,8192,16384,32768 (MB); zero keeps current value
+}
+
+func (ResizeInstancesRequest) ActionName() string { return "ResizeInstances" }
+
+// Validate implements Validator.
+func (r ResizeInstancesRequest) Validate() error {
+	if err := requireIDs(r.ActionName(), "InstanceIDs", r.InstanceIDs); err != nil {
+		return err
+	}
+	if r.Cpu == 0 && r.Mem == 0 {
+		return invalid(r.ActionName(), "Cpu", "or Mem is required")
+	}
+	if r.Cpu != 0 {
+		if err := requireOneOf(r.ActionName(), "Cpu", r.Cpu, cpuChoices...); err != nil {
+			return err
+		}
+	}
+	if r.Mem != 0 {
+		return requireOneOf(r.ActionName(), "Mem", r.Mem, memChoices...)
+	}
+	return nil
+}
+
+// ResizeInstances sends ResizeInstancesRequest to anchnet.
+func (c *Client) ResizeInstances(ctx context.Context, request *ResizeInstancesRequest) (*ResizeInstancesResponse, error) {
+	var response ResizeInstancesResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
+type ResizeInstancesResponse struct {
+	ResponseCommon `json:",inline"`
+	JobID          string `json:"job_id,omitempty"`
+}
+
+// ResizeAndRestartInstances resizes instances which can be running: running
+// instances are stopped, resized, then started again; other instances are only
+// resized. It waits for every job with opts, which can be nil, and returns the
+// response of ResizeInstances. If resizing fails, instances which were running are
+// started again as far as possible, and the resize error is returned. On other
+// errors, instances may be left stopped.
+func (c *Client) ResizeAndRestartInstances(ctx context.Context, request *ResizeInstancesRequest, opts *WaitOptions) (*ResizeInstancesResponse, error) {
+	if request == nil {
+		return nil, fmt.Errorf("expected non-nil request")
+	}
+	// Validate before stopping anything.
+	if !c.SkipValidation {
+		if err := request.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	items, err := c.ListAllInstances(ctx, DescribeInstancesRequest{InstanceIDs: request.InstanceIDs})
+	if err != nil {
+		return nil, err
+	}
+	var running []string
+	for _, item := range items {
+		if item.Status == InstanceStatusRunning {
+			running = append(running, item.InstanceID)
+		}
+	}
+
+	if len(running) > 0 {
+		stop, err := c.StopInstances(ctx, &StopInstancesRequest{InstanceIDs: running, Force: NonForceStop})
+		if err != nil {
+			return nil, err
+		}
+		if err = c.WaitJob(ctx, stop.JobID, opts); err != nil {
+			return nil, err
+		}
+	}
+	resize, err := c.ResizeInstances(ctx, request)
+	if err == nil {
+		err = c.WaitJob(ctx, resize.JobID, opts)
+	}
+	if err != nil {
+		// Best effort, the resize error matters more.
+		c.startInstances(ctx, running, opts)
+		return resize, err
+	}
+	return resize, c.startInstances(ctx, running, opts)
+}
+
+// startInstances starts instances and waits for the job, if there is any instance.
+func (c *Client) startInstances(ctx context.Context, ids []string, opts *WaitOptions) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	start, err := c.StartInstances(ctx, &StartInstancesRequest{InstanceIDs: ids})
+	if err != nil {
+		return err
+	}
+	return c.WaitJob(ctx, start.JobID, opts)
+}
+
+// WaitInstanceStatus waits until all instances reach status, e.g. running, listing
+// them with ListAllInstances on each poll. It returns a *StatusError as soon as any of them
+// becomes suspended, terminated or ceased instead, or is not found. opts can be nil.
+func (c *Client) WaitInstanceStatus(ctx context.Context, ids []string, status InstanceStatus, opts *WaitOptions) error {
+	terminal := []string{string(InstanceStatusSuspended), string(InstanceStatusTerminated), string(InstanceStatusCeased)}
+	return waitStatus(ctx, ids, string(status), terminal, opts, func(ctx context.Context, ids []string) (map[string]string, error) {
+		items, err := c.ListAllInstances(ctx, DescribeInstancesRequest{InstanceIDs: ids})
+		if err != nil {
+			return nil, err
+		}
+		statuses := make(map[string]string)
+		for _, item := range items {
+			statuses[item.InstanceID] = string(item.Status)
+		}
+		return statuses, nil
+	})
+}
+
+// InstancePager pages through DescribeInstances results.
+type InstancePager struct {
+	pager
+	page []DescribeInstancesItem
+}
+
+// NewInstancePager creates a pager for instances matching filter.
+func (c *Client) NewInstancePager(filter DescribeInstancesRequest) *InstancePager {
+	p := &InstancePager{}
+	p.pager = newPager(filter.Offset, filter.Limit, func(ctx context.Context, offset, limit int) (int, int, error) {
+		filter.Offset, filter.Limit = offset, limit
+		p.page = nil
+		response, err := c.DescribeInstances(ctx, &filter)
+		if err != nil {
+			return 0, 0, err
+		}
+		p.page = response.ItemSet
+		return len(response.ItemSet), response.TotalCount, nil
+	})
+	return p
+}
+
+// Page returns instances in the page fetched by the last call to Next.
+func (p *InstancePager) Page() []DescribeInstancesItem {
+	return p.page
+}
+
+// ListAllInstances returns all instances matching filter, following all pages.
+func (c *Client) ListAllInstances(ctx context.Context, filter DescribeInstancesRequest) ([]DescribeInstancesItem, error) {
+	var items []DescribeInstancesItem
+	p := c.NewInstancePager(filter)
+	for p.Next(ctx) {
+		items = append(items, p.Page()...)
+	}
+	return items, p.Err()
+}
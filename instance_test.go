@@ -8,6 +8,9 @@ import (
 	"net/http/httptest"
 	"reflect"
 	"testing"
+	"time"
+
+	"github.com/caicloud/anchnet-go/testutil"
 )
 
 // TestDescribeInstances tests that we send correct request to describe instances.
@@ -25,7 +28,7 @@ func TestDescribeInstances(t *testing.T) {
 }
 `)
 
-	fakeResponse := RemoveWhitespaces(`
+	fakeResponse := `
 {
   "ret_code": 0,
   "action": "DescribeInstancesResponse",
@@ -102,9 +105,9 @@ func TestDescribeInstances(t *testing.T) {
   "code": 0,
   "total_count": 1
 }
-`)
+`
 
-	testServer := httptest.NewServer(&FakeHandler{t: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
+	testServer := httptest.NewServer(&testutil.FakeHandler{T: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
 	defer testServer.Close()
 
 	c, err := NewClient(testServer.URL, &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"})
@@ -143,8 +146,8 @@ func TestDescribeInstances(t *testing.T) {
 				Status:        InstanceStatusRunning,
 				VcpusCurrent:  1,
 				MemoryCurrent: 1024,
-				StatusTime:    "2015-02-1511:10:37",
-				CreateTime:    "2015-02-1511:10:37",
+				StatusTime:    Time{time.Date(2015, 2, 15, 11, 10, 37, 0, location)},
+				CreateTime:    Time{time.Date(2015, 2, 15, 11, 10, 37, 0, location)},
 				Vxnets: []DescribeInstancesVxnet{
 					{
 						VxnetID:   "vxnet-0",
@@ -178,7 +181,7 @@ func TestDescribeInstances(t *testing.T) {
 				},
 				Image: DescribeInstancesImage{
 					ImageID:       "centos65x64d",
-					ImageName:     "CentOS6.564bit",
+					ImageName:     "CentOS 6.5 64bit",
 					ImageSize:     20,
 					OsFamily:      "centos",
 					Platform:      "linux",
@@ -187,10 +190,10 @@ func TestDescribeInstances(t *testing.T) {
 				VolumeIDs: []string{"vom-QBU4NHSP"},
 				Volumes: []DescribeInstancesVolume{
 					{
-						Size:       "10",
+						Size:       10,
 						VolumeID:   "vom-QBU4NHSP",
 						VolumeName: "gao",
-						VolumeType: "1",
+						VolumeType: VolumeTypeCapacity,
 					},
 				},
 				SecurityGroup: DescribeInstancesSecurityGroup{
@@ -221,7 +224,8 @@ func TestRunInstances(t *testing.T) {
         "mem": 1024,
         "cpu": 1,
         "image_id": "centos65x64d",
-        "password": "1111ssSS"
+        "password": "1111ssSS",
+        "userdata": "IyEvYmluL3NoCmVjaG8gaGVsbG8K"
       },
       "hd": [
         {
@@ -278,7 +282,7 @@ func TestRunInstances(t *testing.T) {
 }
 `)
 
-	testServer := httptest.NewServer(&FakeHandler{t: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
+	testServer := httptest.NewServer(&testutil.FakeHandler{T: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
 	defer testServer.Close()
 
 	c, err := NewClient(testServer.URL, &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"})
@@ -297,6 +301,7 @@ func TestRunInstances(t *testing.T) {
 					Cpu:       1,
 					Password:  "1111ssSS",
 					ImageID:   "centos65x64d",
+					UserData:  []byte("#!/bin/sh\necho hello\n"),
 				},
 				HD: []RunInstancesHardDisk{
 					{
@@ -372,7 +377,7 @@ func TestTerminateInstances(t *testing.T) {
 }
 `)
 
-	testServer := httptest.NewServer(&FakeHandler{t: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
+	testServer := httptest.NewServer(&testutil.FakeHandler{T: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
 	defer testServer.Close()
 
 	c, err := NewClient(testServer.URL, &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"})
@@ -426,7 +431,7 @@ func TestStartInstances(t *testing.T) {
 }
 `)
 
-	testServer := httptest.NewServer(&FakeHandler{t: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
+	testServer := httptest.NewServer(&testutil.FakeHandler{T: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
 	defer testServer.Close()
 
 	c, err := NewClient(testServer.URL, &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"})
@@ -482,7 +487,7 @@ func TestStopInstance(t *testing.T) {
 }
 `)
 
-	testServer := httptest.NewServer(&FakeHandler{t: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
+	testServer := httptest.NewServer(&testutil.FakeHandler{T: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
 	defer testServer.Close()
 
 	c, err := NewClient(testServer.URL, &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"})
@@ -537,7 +542,7 @@ func TestRestartInstances(t *testing.T) {
 }
 `)
 
-	testServer := httptest.NewServer(&FakeHandler{t: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
+	testServer := httptest.NewServer(&testutil.FakeHandler{T: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
 	defer testServer.Close()
 
 	c, err := NewClient(testServer.URL, &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"})
@@ -568,6 +573,63 @@ func TestRestartInstances(t *testing.T) {
 	}
 }
 
+// TestResizeInstances tests that we send correct request to resize instances.
+func TestResizeInstances(t *testing.T) {
+	expectedJson := RemoveWhitespaces(`
+{
+  "instances": [
+    "i-G74Q69NJ"
+  ],
+  "cpu": 2,
+  "mem": 4096,
+  "zone": "ac1",
+  "token":"E5I9QKJF1O2B5PXE68LG",
+  "action": "ResizeInstances"
+}
+`)
+
+	fakeResponse := RemoveWhitespaces(`
+{
+  "ret_code":0,
+  "action": "ResizeInstancesResponse",
+  "code": 0,
+  "job_id": "job-4KHIYWDN"
+}
+`)
+
+	testServer := httptest.NewServer(&testutil.FakeHandler{T: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
+	defer testServer.Close()
+
+	c, err := NewClient(testServer.URL, &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"})
+	if err != nil {
+		t.Errorf("Unexpected non-nil error %v", err)
+	}
+
+	request := ResizeInstancesRequest{
+		InstanceIDs: []string{"i-G74Q69NJ"},
+		Cpu:         2,
+		Mem:         4096,
+	}
+	var response ResizeInstancesResponse
+
+	err = c.SendRequest(request, &response)
+	if err != nil {
+		t.Errorf("Unexpected non-nil error %v", err)
+	}
+
+	expectedResponse := ResizeInstancesResponse{
+		ResponseCommon: ResponseCommon{
+			Action:  "ResizeInstancesResponse",
+			Code:    0,
+			RetCode: 0,
+		},
+		JobID: "job-4KHIYWDN",
+	}
+	if !reflect.DeepEqual(expectedResponse, response) {
+		t.Errorf("Error: expected \n%v, got \n%v", expectedResponse, response)
+	}
+}
+
 // TestResetLoginPasswd tests that we send correct request to reset instance password.
 func TestResetLoginPasswd(t *testing.T) {
 	expectedJson := RemoveWhitespaces(`
@@ -591,7 +653,7 @@ func TestResetLoginPasswd(t *testing.T) {
 }
 `)
 
-	testServer := httptest.NewServer(&FakeHandler{t: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
+	testServer := httptest.NewServer(&testutil.FakeHandler{T: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
 	defer testServer.Close()
 
 	c, err := NewClient(testServer.URL, &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"})
@@ -646,7 +708,7 @@ func TestModifyInstanceAttributes(t *testing.T) {
 }
 `)
 
-	testServer := httptest.NewServer(&FakeHandler{t: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
+	testServer := httptest.NewServer(&testutil.FakeHandler{T: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
 	defer testServer.Close()
 
 	c, err := NewClient(testServer.URL, &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"})
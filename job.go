@@ -4,6 +4,13 @@
 
 package anchnet
 
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+)
+
 // Implements all anchnet job related APIs. Job is not a type of resource
 // in anchnet, it's used to query other request status.
 
@@ -15,6 +22,15 @@ type DescribeJobsRequest struct {
 	JobIDs        []string `json:"jobs,omitempty"`
 }
 
+func (DescribeJobsRequest) ActionName() string { return "DescribeJobs" }
+
+// DescribeJobs sends DescribeJobsRequest to anchnet.
+func (c *Client) DescribeJobs(ctx context.Context, request *DescribeJobsRequest) (*DescribeJobsResponse, error) {
+	var response DescribeJobsResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type DescribeJobsResponse struct {
 	ResponseCommon `json:",inline"`
 	TotalCount     int                `json:"total_count,omitempty"`
@@ -22,10 +38,11 @@ type DescribeJobsResponse struct {
 }
 
 type DescribeJobsItem struct {
+	JobID      string    `json:"job_id,omitempty"`
 	JobAction  string    `json:"job_action,omitempty"`
 	Status     JobStatus `json:"status,omitempty"`      // Status of the job
-	StatusTime string    `json:"status_time,omitempty"` // Last date when job was changed
-	CreateTime string    `json:"create_time,omitempty"` // Date when job was created
+	StatusTime Time      `json:"status_time,omitempty"` // Last date when job was changed
+	CreateTime Time      `json:"create_time,omitempty"` // Date when job was created
 }
 
 type JobStatus string
@@ -36,3 +53,104 @@ const (
 	JobStatusSuccessful JobStatus = "successful"
 	JobStatusFailed     JobStatus = "failed"
 )
+
+// JobError is returned by job waiters when a job fails, or is not found. Job is
+// the last information retrieved from anchnet, empty if the job is not found.
+type JobError struct {
+	JobID string
+	Job   DescribeJobsItem
+}
+
+func (e *JobError) Error() string {
+	if e.Job.Status == "" {
+		return fmt.Sprintf("job %v is not found", e.JobID)
+	}
+	return fmt.Sprintf("job %v (%v) is %v", e.JobID, e.Job.JobAction, e.Job.Status)
+}
+
+// WaitJob waits until a job becomes successful. It returns a *JobError if the job
+// fails or doesn't exist. opts can be nil, in which case DefaultWaitOptions is used.
+func (c *Client) WaitJob(ctx context.Context, jobID string, opts *WaitOptions) error {
+	return c.WaitJobStatus(ctx, jobID, JobStatusSuccessful, opts)
+}
+
+// WaitJobStatus waits until a job reaches the given status. It returns a *JobError
+// if the job fails while waiting for another status.
+func (c *Client) WaitJobStatus(ctx context.Context, jobID string, status JobStatus, opts *WaitOptions) error {
+	return c.waitJobs(ctx, []string{jobID}, status, opts)
+}
+
+// WaitJobs waits until all jobs become successful. Pending jobs are described
+// with a single DescribeJobs call per poll. It returns a *JobError as soon as
+// any of the jobs fails.
+func (c *Client) WaitJobs(ctx context.Context, jobIDs []string, opts *WaitOptions) error {
+	return c.waitJobs(ctx, jobIDs, JobStatusSuccessful, opts)
+}
+
+func (c *Client) waitJobs(ctx context.Context, jobIDs []string, status JobStatus, opts *WaitOptions) error {
+	pending := make(map[string]bool)
+	for _, id := range jobIDs {
+		pending[id] = true
+	}
+	err := poll(ctx, opts, func(ctx context.Context) (bool, error) {
+		var describeIDs []string
+		for id := range pending {
+			describeIDs = append(describeIDs, id)
+		}
+		sort.Strings(describeIDs)
+		items, err := c.describeJobs(ctx, describeIDs)
+		if err != nil {
+			return false, err
+		}
+		for _, id := range describeIDs {
+			item, ok := items[id]
+			if !ok {
+				// Unknown jobs, e.g. mistyped or expired IDs, would be pending forever.
+				return false, &JobError{JobID: id}
+			}
+			if item.Status == status {
+				delete(pending, id)
+			} else if item.Status == JobStatusFailed {
+				return false, &JobError{JobID: id, Job: item}
+			}
+		}
+		return len(pending) == 0, nil
+	})
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return fmt.Errorf("error waiting for jobs %v: %w", jobIDs, err)
+	}
+	return err
+}
+
+// describeJobs describes jobs, keyed by job ID. Job ID may be missing from the
+// items in the response, in which case the jobs are described one at a time to
+// tell them apart.
+func (c *Client) describeJobs(ctx context.Context, jobIDs []string) (map[string]DescribeJobsItem, error) {
+	response, err := c.DescribeJobs(ctx, &DescribeJobsRequest{JobIDs: jobIDs})
+	if err != nil {
+		return nil, err
+	}
+	items := make(map[string]DescribeJobsItem)
+	for _, item := range response.ItemSet {
+		if item.JobID != "" {
+			items[item.JobID] = item
+			continue
+		}
+		if len(jobIDs) == 1 {
+			items[jobIDs[0]] = item
+			continue
+		}
+		items = make(map[string]DescribeJobsItem)
+		for _, id := range jobIDs {
+			single, err := c.describeJobs(ctx, []string{id})
+			if err != nil {
+				return nil, err
+			}
+			for id, item := range single {
+				items[id] = item
+			}
+		}
+		return items, nil
+	}
+	return items, nil
+}
@@ -0,0 +1,165 @@
+// Copyright 2015 anchnet-go authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package anchnet
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeJobsHandler serves DescribeJobs. Each job walks through its statuses, one
+// per request describing it; the last status sticks. The first 'unavailable'
+// requests fail with http 503, and job IDs are left out of responses if omitIDs
+// is set.
+type fakeJobsHandler struct {
+	statuses    map[string][]JobStatus
+	unavailable int
+	omitIDs     bool
+
+	mu       sync.Mutex
+	requests [][]string
+}
+
+func (f *fakeJobsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	body, _ := ioutil.ReadAll(r.Body)
+	var request DescribeJobsRequest
+	json.Unmarshal(body, &request)
+	sort.Strings(request.JobIDs)
+	f.requests = append(f.requests, request.JobIDs)
+	if f.unavailable > 0 {
+		f.unavailable--
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	var response DescribeJobsResponse
+	for _, id := range request.JobIDs {
+		statuses := f.statuses[id]
+		jobID := id
+		if f.omitIDs {
+			jobID = ""
+		}
+		response.ItemSet = append(response.ItemSet, DescribeJobsItem{
+			JobID:     jobID,
+			JobAction: "RunInstances",
+			Status:    statuses[0],
+		})
+		if len(statuses) > 1 {
+			f.statuses[id] = statuses[1:]
+		}
+	}
+	response.TotalCount = len(response.ItemSet)
+	json.NewEncoder(w).Encode(response)
+}
+
+// TestWaitJobs tests that job waiters poll pending jobs in batch, and stop on failure.
+func TestWaitJobs(t *testing.T) {
+	opts := &WaitOptions{Interval: time.Millisecond, Backoff: 2, MaxInterval: 4 * time.Millisecond, Timeout: time.Second}
+
+	tests := []struct {
+		jobIDs           []string
+		statuses         map[string][]JobStatus
+		unavailable      int
+		omitIDs          bool
+		expectedError    error
+		expectedRequests [][]string
+	}{
+		{
+			jobIDs: []string{"job-1"},
+			statuses: map[string][]JobStatus{
+				"job-1": {JobStatusPending, JobStatusWorking, JobStatusSuccessful},
+			},
+			expectedRequests: [][]string{{"job-1"}, {"job-1"}, {"job-1"}},
+		},
+		{
+			jobIDs: []string{"job-1", "job-2"},
+			statuses: map[string][]JobStatus{
+				"job-1": {JobStatusWorking, JobStatusSuccessful},
+				"job-2": {JobStatusWorking, JobStatusWorking, JobStatusSuccessful},
+			},
+			expectedRequests: [][]string{{"job-1", "job-2"}, {"job-1", "job-2"}, {"job-2"}},
+		},
+		{
+			jobIDs: []string{"job-1", "job-2"},
+			statuses: map[string][]JobStatus{
+				"job-1": {JobStatusWorking, JobStatusWorking, JobStatusSuccessful},
+				"job-2": {JobStatusWorking, JobStatusFailed},
+			},
+			expectedError: &JobError{
+				JobID: "job-2",
+				Job:   DescribeJobsItem{JobID: "job-2", JobAction: "RunInstances", Status: JobStatusFailed},
+			},
+			expectedRequests: [][]string{{"job-1", "job-2"}, {"job-1", "job-2"}},
+		},
+		{
+			// Transient errors don't stop waiting.
+			jobIDs: []string{"job-1"},
+			statuses: map[string][]JobStatus{
+				"job-1": {JobStatusSuccessful},
+			},
+			unavailable:      2,
+			expectedRequests: [][]string{{"job-1"}, {"job-1"}, {"job-1"}},
+		},
+		{
+			// Without job IDs in the response, jobs are described one at a time.
+			jobIDs: []string{"job-1", "job-2"},
+			statuses: map[string][]JobStatus{
+				"job-1": {JobStatusWorking, JobStatusWorking, JobStatusSuccessful},
+				"job-2": {JobStatusWorking, JobStatusSuccessful},
+			},
+			omitIDs:          true,
+			expectedRequests: [][]string{{"job-1", "job-2"}, {"job-1"}, {"job-2"}, {"job-1"}},
+		},
+	}
+
+	for i, test := range tests {
+		handler := &fakeJobsHandler{statuses: test.statuses, unavailable: test.unavailable, omitIDs: test.omitIDs}
+		testServer := httptest.NewServer(handler)
+
+		c, err := NewClient(testServer.URL, &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"})
+		if err != nil {
+			t.Errorf("Unexpected non-nil error %v", err)
+		}
+		c.RetryPolicy = NoRetry
+
+		err = c.WaitJobs(context.Background(), test.jobIDs, opts)
+		if !reflect.DeepEqual(test.expectedError, err) {
+			t.Errorf("Test %d: expected error %v, got %v", i, test.expectedError, err)
+		}
+		if !reflect.DeepEqual(test.expectedRequests, handler.requests) {
+			t.Errorf("Test %d: expected requests %v, got %v", i, test.expectedRequests, handler.requests)
+		}
+		testServer.Close()
+	}
+}
+
+// TestWaitJobTimeout tests that WaitJob gives up after timeout.
+func TestWaitJobTimeout(t *testing.T) {
+	handler := &fakeJobsHandler{statuses: map[string][]JobStatus{"job-1": {JobStatusWorking}}}
+	testServer := httptest.NewServer(handler)
+	defer testServer.Close()
+
+	c, err := NewClient(testServer.URL, &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"})
+	if err != nil {
+		t.Errorf("Unexpected non-nil error %v", err)
+	}
+
+	err = c.WaitJob(context.Background(), "job-1", &WaitOptions{Interval: time.Millisecond, Timeout: 20 * time.Millisecond})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded error, got %v", err)
+	}
+}
@@ -0,0 +1,198 @@
+// Copyright 2015 anchnet-go authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package anchnet
+
+import (
+	"context"
+)
+
+// Implements all anchnet keypair (SSH key) related APIs.
+
+//
+// DescribeKeyPairs retrieves information of a list of keypairs.
+//
+type DescribeKeyPairsRequest struct {
+	RequestCommon `json:",inline"`
+	KeyPairIDs    []string `json:"keypairs,omitempty"`
+	InstanceID    string   `json:"instance_id,omitempty"` // Only describe keypairs attached to the instance
+	SearchWord    string   `json:"search_word,omitempty"`
+	Verbose       int      `json:"verbose,omitempty"`
+	Offset        int      `json:"offset,omitempty"`
+	Limit         int      `json:"limit,omitempty"`
+}
+
+func (DescribeKeyPairsRequest) ActionName() string { return "DescribeKeyPairs" }
+
+// DescribeKeyPairs sends DescribeKeyPairsRequest to anchnet.
+func (c *Client) DescribeKeyPairs(ctx context.Context, request *DescribeKeyPairsRequest) (*DescribeKeyPairsResponse, error) {
+	var response DescribeKeyPairsResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
+type DescribeKeyPairsResponse struct {
+	ResponseCommon `json:",inline"`
+	TotalCount     int                    `json:"total_count,omitempty"`
+	ItemSet        []DescribeKeyPairsItem `json:"item_set,omitempty"`
+}
+
+type DescribeKeyPairsItem struct {
+	KeyPairID     string               `json:"keypair_id,omitempty"`
+	KeyPairName   string               `json:"keypair_name,omitempty"`
+	Description   string               `json:"description,omitempty"`
+	EncryptMethod KeyPairEncryptMethod `json:"encrypt_method,omitempty"`
+	PubKey        string               `json:"pub_key,omitempty"`
+	CreateTime    Time                 `json:"create_time,omitempty"`
+	InstanceIDs   []string             `json:"instance_ids,omitempty"` // Instances the keypair is attached to
+}
+
+// KeyPairMode specifies who generates a new keypair.
+type KeyPairMode string
+
+const (
+	// KeyPairModeSystem lets anchnet generate the keypair; the private key is only
+	// returned by CreateKeyPair.
+	KeyPairModeSystem KeyPairMode = "system"
+	// KeyPairModeUser creates a keypair from a given public key.
+	KeyPairModeUser KeyPairMode = "user"
+)
+
+type KeyPairEncryptMethod string
+
+const (
+	KeyPairEncryptMethodRSA KeyPairEncryptMethod = "ssh-rsa"
+	KeyPairEncryptMethodDSS KeyPairEncryptMethod = "ssh-dss"
+)
+
+//
+// CreateKeyPair creates a keypair, either generated by anchnet or from a public key.
+//
+type CreateKeyPairRequest struct {
+	RequestCommon `json:",inline"`
+	KeyPairName   string               `json:"keypair_name,omitempty"`
+	Mode          KeyPairMode          `json:"mode,omitempty"`
+	EncryptMethod KeyPairEncryptMethod `json:"encrypt_method,omitempty"` // Used in system mode
+	PublicKey     string               `json:"public_key,omitempty"`     // Used in user mode, e.g. content of id_rsa.pub
+}
+
+func (CreateKeyPairRequest) ActionName() string { return "CreateKeyPair" }
+
+// Validate implements Validator.
+func (r CreateKeyPairRequest) Validate() error {
+	err := firstError(
+		requireID(r.ActionName(), "KeyPairName", r.KeyPairName),
+		requireOneOf(r.ActionName(), "Mode", r.Mode, KeyPairModeSystem, KeyPairModeUser),
+	)
+	if err != nil {
+		return err
+	}
+	if r.Mode == KeyPairModeUser {
+		return requireID(r.ActionName(), "PublicKey", r.PublicKey)
+	}
+	return requireOneOf(r.ActionName(), "EncryptMethod", r.EncryptMethod, KeyPairEncryptMethodRSA, KeyPairEncryptMethodDSS)
+}
+
+// CreateKeyPair sends CreateKeyPairRequest to anchnet.
+func (c *Client) CreateKeyPair(ctx context.Context, request *CreateKeyPairRequest) (*CreateKeyPairResponse, error) {
+	var response CreateKeyPairResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
+type CreateKeyPairResponse struct {
+	ResponseCommon `json:",inline"`
+	KeyPairID      string `json:"keypair_id,omitempty"`
+	PrivateKey     string `json:"private_key,omitempty"` // Only set in system mode; save it, it can't be retrieved later
+}
+
+//
+// DeleteKeyPairs deletes a list of keypairs. Keypairs must be detached first.
+//
+type DeleteKeyPairsRequest struct {
+	RequestCommon `json:",inline"`
+	KeyPairIDs    []string `json:"keypairs,omitempty"`
+}
+
+func (DeleteKeyPairsRequest) ActionName() string { return "DeleteKeyPairs" }
+
+// Validate implements Validator.
+func (r DeleteKeyPairsRequest) Validate() error {
+	return requireIDs(r.ActionName(), "KeyPairIDs", r.KeyPairIDs)
+}
+
+// DeleteKeyPairs sends DeleteKeyPairsRequest to anchnet.
+func (c *Client) DeleteKeyPairs(ctx context.Context, request *DeleteKeyPairsRequest) (*DeleteKeyPairsResponse, error) {
+	var response DeleteKeyPairsResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
+type DeleteKeyPairsResponse struct {
+	ResponseCommon `json:",inline"`
+	JobID          string `json:"job_id,omitempty"`
+}
+
+//
+// AttachKeyPairs attaches a list of keypairs to a list of instances, i.e. adds their
+// public keys to authorized keys of the instances.
+//
+type AttachKeyPairsRequest struct {
+	RequestCommon `json:",inline"`
+	KeyPairIDs    []string `json:"keypairs,omitempty"`
+	InstanceIDs   []string `json:"instances,omitempty"`
+}
+
+func (AttachKeyPairsRequest) ActionName() string { return "AttachKeyPairs" }
+
+// Validate implements Validator.
+func (r AttachKeyPairsRequest) Validate() error {
+	return firstError(
+		requireIDs(r.ActionName(), "KeyPairIDs", r.KeyPairIDs),
+		requireIDs(r.ActionName(), "InstanceIDs", r.InstanceIDs),
+	)
+}
+
+// AttachKeyPairs sends AttachKeyPairsRequest to anchnet.
+func (c *Client) AttachKeyPairs(ctx context.Context, request *AttachKeyPairsRequest) (*AttachKeyPairsResponse, error) {
+	var response AttachKeyPairsResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
+type AttachKeyPairsResponse struct {
+	ResponseCommon `json:",inline"`
+	JobID          string `json:"job_id,omitempty"`
+}
+
+//
+// DetachKeyPairs detaches a list of keypairs from a list of instances.
+//
+type DetachKeyPairsRequest struct {
+	RequestCommon `json:",inline"`
+	KeyPairIDs    []string `json:"keypairs,omitempty"`
+	InstanceIDs   []string `json:"instances,omitempty"`
+}
+
+func (DetachKeyPairsRequest) ActionName() string { return "DetachKeyPairs" }
+
+// Validate implements Validator.
+func (r DetachKeyPairsRequest) Validate() error {
+	return firstError(
+		requireIDs(r.ActionName(), "KeyPairIDs", r.KeyPairIDs),
+		requireIDs(r.ActionName(), "InstanceIDs", r.InstanceIDs),
+	)
+}
+
+// DetachKeyPairs sends DetachKeyPairsRequest to anchnet.
+func (c *Client) DetachKeyPairs(ctx context.Context, request *DetachKeyPairsRequest) (*DetachKeyPairsResponse, error) {
+	var response DetachKeyPairsResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
+type DetachKeyPairsResponse struct {
+	ResponseCommon `json:",inline"`
+	JobID          string `json:"job_id,omitempty"`
+}
@@ -0,0 +1,205 @@
+// Copyright 2015 anchnet-go authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package anchnet
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/caicloud/anchnet-go/testutil"
+)
+
+// TestDescribeKeyPairs tests that we send correct request to describe keypairs.
+func TestDescribeKeyPairs(t *testing.T) {
+	expectedJson := RemoveWhitespaces(`
+{
+  "keypairs": [
+    "kp-L4DLJM8W"
+  ],
+  "token": "E5I9QKJF1O2B5PXE68LG",
+  "action": "DescribeKeyPairs",
+  "zone": "ac1"
+}
+`)
+
+	fakeResponse := `
+{
+  "ret_code": 0,
+  "action": "DescribeKeyPairsResponse",
+  "item_set": [
+    {
+      "keypair_id": "kp-L4DLJM8W",
+      "keypair_name": "deploy",
+      "description": "",
+      "encrypt_method": "ssh-rsa",
+      "pub_key": "ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAABAQC7 deploy",
+      "create_time": "2015-08-03 16:25:08",
+      "instance_ids": [
+        "i-G74Q69NJ"
+      ]
+    }
+  ],
+  "code": 0,
+  "total_count": 1
+}
+`
+
+	testServer := httptest.NewServer(&testutil.FakeHandler{T: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
+	defer testServer.Close()
+
+	c, err := NewClient(testServer.URL, &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"})
+	if err != nil {
+		t.Errorf("Unexpected non-nil error %v", err)
+	}
+
+	request := DescribeKeyPairsRequest{
+		KeyPairIDs: []string{"kp-L4DLJM8W"},
+	}
+	var response DescribeKeyPairsResponse
+
+	err = c.SendRequest(request, &response)
+	if err != nil {
+		t.Errorf("Unexpected non-nil error %v", err)
+	}
+
+	expectedResponse := DescribeKeyPairsResponse{
+		ResponseCommon: ResponseCommon{
+			Action:  "DescribeKeyPairsResponse",
+			Code:    0,
+			RetCode: 0,
+		},
+		TotalCount: 1,
+		ItemSet: []DescribeKeyPairsItem{
+			{
+				KeyPairID:     "kp-L4DLJM8W",
+				KeyPairName:   "deploy",
+				EncryptMethod: KeyPairEncryptMethodRSA,
+				PubKey:        "ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAABAQC7 deploy",
+				CreateTime:    Time{time.Date(2015, 8, 3, 16, 25, 8, 0, location)},
+				InstanceIDs:   []string{"i-G74Q69NJ"},
+			},
+		},
+	}
+	if !reflect.DeepEqual(expectedResponse, response) {
+		t.Errorf("Error: expected \n%v, got \n%v", expectedResponse, response)
+	}
+}
+
+// TestCreateKeyPair tests that we send correct request to create keypair.
+func TestCreateKeyPair(t *testing.T) {
+	expectedJson := RemoveWhitespaces(`
+{
+  "keypair_name": "deploy",
+  "mode": "system",
+  "encrypt_method": "ssh-rsa",
+  "token": "E5I9QKJF1O2B5PXE68LG",
+  "action": "CreateKeyPair",
+  "zone": "ac1"
+}
+`)
+
+	fakeResponse := RemoveWhitespaces(`
+{
+  "ret_code": 0,
+  "action": "CreateKeyPairResponse",
+  "code": 0,
+  "keypair_id": "kp-L4DLJM8W",
+  "private_key": "-----BEGINRSAPRIVATEKEY-----"
+}
+`)
+
+	testServer := httptest.NewServer(&testutil.FakeHandler{T: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
+	defer testServer.Close()
+
+	c, err := NewClient(testServer.URL, &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"})
+	if err != nil {
+		t.Errorf("Unexpected non-nil error %v", err)
+	}
+
+	request := CreateKeyPairRequest{
+		KeyPairName:   "deploy",
+		Mode:          KeyPairModeSystem,
+		EncryptMethod: KeyPairEncryptMethodRSA,
+	}
+	var response CreateKeyPairResponse
+
+	err = c.SendRequest(request, &response)
+	if err != nil {
+		t.Errorf("Unexpected non-nil error %v", err)
+	}
+
+	expectedResponse := CreateKeyPairResponse{
+		ResponseCommon: ResponseCommon{
+			Action:  "CreateKeyPairResponse",
+			Code:    0,
+			RetCode: 0,
+		},
+		KeyPairID:  "kp-L4DLJM8W",
+		PrivateKey: "-----BEGINRSAPRIVATEKEY-----",
+	}
+	if !reflect.DeepEqual(expectedResponse, response) {
+		t.Errorf("Error: expected \n%v, got \n%v", expectedResponse, response)
+	}
+}
+
+// TestAttachKeyPairs tests that we send correct request to attach keypairs.
+func TestAttachKeyPairs(t *testing.T) {
+	expectedJson := RemoveWhitespaces(`
+{
+  "keypairs": [
+    "kp-L4DLJM8W"
+  ],
+  "instances": [
+    "i-G74Q69NJ",
+    "i-OAEZPC6C"
+  ],
+  "token": "E5I9QKJF1O2B5PXE68LG",
+  "action": "AttachKeyPairs",
+  "zone": "ac1"
+}
+`)
+
+	fakeResponse := RemoveWhitespaces(`
+{
+  "ret_code": 0,
+  "action": "AttachKeyPairsResponse",
+  "code": 0,
+  "job_id": "job-WLZ8L2S6"
+}
+`)
+
+	testServer := httptest.NewServer(&testutil.FakeHandler{T: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
+	defer testServer.Close()
+
+	c, err := NewClient(testServer.URL, &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"})
+	if err != nil {
+		t.Errorf("Unexpected non-nil error %v", err)
+	}
+
+	request := AttachKeyPairsRequest{
+		KeyPairIDs:  []string{"kp-L4DLJM8W"},
+		InstanceIDs: []string{"i-G74Q69NJ", "i-OAEZPC6C"},
+	}
+	var response AttachKeyPairsResponse
+
+	err = c.SendRequest(request, &response)
+	if err != nil {
+		t.Errorf("Unexpected non-nil error %v", err)
+	}
+
+	expectedResponse := AttachKeyPairsResponse{
+		ResponseCommon: ResponseCommon{
+			Action:  "AttachKeyPairsResponse",
+			Code:    0,
+			RetCode: 0,
+		},
+		JobID: "job-WLZ8L2S6",
+	}
+	if !reflect.DeepEqual(expectedResponse, response) {
+		t.Errorf("Error: expected \n%v, got \n%v", expectedResponse, response)
+	}
+}
@@ -4,6 +4,11 @@
 
 package anchnet
 
+import (
+	"context"
+	"fmt"
+)
+
 // Implements all anchnet loadbalancer related APIs, except loadbalancer policy related.
 
 //
@@ -19,6 +24,15 @@ type DescribeLoadBalancersRequest struct {
 	Limit           int                  `json:"limit,omitempty"`
 }
 
+func (DescribeLoadBalancersRequest) ActionName() string { return "DescribeLoadBalancers" }
+
+// DescribeLoadBalancers sends DescribeLoadBalancersRequest to anchnet.
+func (c *Client) DescribeLoadBalancers(ctx context.Context, request *DescribeLoadBalancersRequest) (*DescribeLoadBalancersResponse, error) {
+	var response DescribeLoadBalancersResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type DescribeLoadBalancersResponse struct {
 	ResponseCommon `json:",inline"`
 	TotalCount     int                         `json:"total_count,omitempty"`
@@ -30,8 +44,8 @@ type DescribeLoadBalancersItem struct {
 	LoadbalancerName string                             `json:"loadbalancer_name,omitempty"`
 	LoadbalancerType LoadBalancerType                   `json:"loadbalancer_type,omitempty"`
 	Description      string                             `json:"description,omitempty"`
-	CreateTime       string                             `json:"create_time,omitempty"`
-	StatusTime       string                             `json:"status_time,omitempty"`
+	CreateTime       Time                               `json:"create_time,omitempty"`
+	StatusTime       Time                               `json:"status_time,omitempty"`
 	IsApplied        int                                `json:"is_applied,omitempty"`
 	Status           LoadBalancerStatus                 `json:"status,omitempty"`
 	Eips             []DescribeLoadBalancersEIP         `json:"eips,omitempty"`
@@ -77,6 +91,25 @@ type ListenerOptions struct {
 	Timeout            int                  `json:"timeout,omitempty"`
 }
 
+// validate checks options of a request of action, which are all optional. prefix
+// is prepended to field names in errors.
+func (o ListenerOptions) validate(action, prefix string) error {
+	var errs []error
+	if o.BalanceMode != "" {
+		errs = append(errs, requireOneOf(action, prefix+"BalanceMode", o.BalanceMode,
+			BalanceModeRoundRobin, BalanceModeRoundLeastConn, BalanceModeSource))
+	}
+	if o.ListenerProtocol != "" {
+		errs = append(errs, requireOneOf(action, prefix+"ListenerProtocol", o.ListenerProtocol,
+			ListenerProtocolTypeHTTP, ListenerProtocolTypeTCP))
+	}
+	if o.BackendProtocol != "" {
+		errs = append(errs, requireOneOf(action, prefix+"BackendProtocol", o.BackendProtocol,
+			BackendProtocolTypeHTTP, BackendProtocolTypeTCP))
+	}
+	return firstError(errs...)
+}
+
 // LoadBalancerType defines the max concurrent connections allowed on loadbalancer.
 type LoadBalancerType int
 
@@ -112,6 +145,7 @@ const (
 	LoadBalancerStatusStopped   LoadBalancerStatus = "stopped"
 	LoadBalancerStatusSuspended LoadBalancerStatus = "suspended"
 	LoadBalancerStatusDeleted   LoadBalancerStatus = "deleted"
+	LoadBalancerStatusCeased    LoadBalancerStatus = "ceased"
 )
 
 // BalanceMode defines how to do load balance.
@@ -131,6 +165,21 @@ type CreateLoadBalancerRequest struct {
 	Product       CreateLoadBalancerProduct `json:"product,omitempty"`
 }
 
+func (CreateLoadBalancerRequest) ActionName() string { return "CreateLoadBalancer" }
+
+// Validate implements Validator.
+func (r CreateLoadBalancerRequest) Validate() error {
+	return requireOneOf(r.ActionName(), "Product.Loadbalancer.Type", r.Product.Loadbalancer.Type,
+		LoadBalancerType20K, LoadBalancerType40K, LoadBalancerType100K)
+}
+
+// CreateLoadBalancer sends CreateLoadBalancerRequest to anchnet.
+func (c *Client) CreateLoadBalancer(ctx context.Context, request *CreateLoadBalancerRequest) (*CreateLoadBalancerResponse, error) {
+	var response CreateLoadBalancerResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type CreateLoadBalancerResponse struct {
 	ResponseCommon `json:",inline"`
 	JobID          string `json:"job_id,omitempty"`
@@ -165,6 +214,20 @@ type DeleteLoadBalancersRequest struct {
 	EipIDs          []string `json:"ips,omitempty"`
 }
 
+func (DeleteLoadBalancersRequest) ActionName() string { return "DeleteLoadBalancers" }
+
+// Validate implements Validator.
+func (r DeleteLoadBalancersRequest) Validate() error {
+	return requireIDs(r.ActionName(), "LoadbalancerIDs", r.LoadbalancerIDs)
+}
+
+// DeleteLoadBalancers sends DeleteLoadBalancersRequest to anchnet.
+func (c *Client) DeleteLoadBalancers(ctx context.Context, request *DeleteLoadBalancersRequest) (*DeleteLoadBalancersResponse, error) {
+	var response DeleteLoadBalancersResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type DeleteLoadBalancersResponse struct {
 	ResponseCommon `json:",inline"`
 	JobID          string `json:"job_id,omitempty"`
@@ -178,6 +241,20 @@ type StartLoadBalancersRequest struct {
 	LoadbalancerIDs []string `json:"loadbalancers,omitempty"`
 }
 
+func (StartLoadBalancersRequest) ActionName() string { return "StartLoadBalancer" }
+
+// Validate implements Validator.
+func (r StartLoadBalancersRequest) Validate() error {
+	return requireIDs(r.ActionName(), "LoadbalancerIDs", r.LoadbalancerIDs)
+}
+
+// StartLoadBalancer sends StartLoadBalancersRequest to anchnet.
+func (c *Client) StartLoadBalancer(ctx context.Context, request *StartLoadBalancersRequest) (*StartLoadBalancersResponse, error) {
+	var response StartLoadBalancersResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type StartLoadBalancersResponse struct {
 	ResponseCommon `json:",inline"`
 	JobID          string `json:"job_id,omitempty"`
@@ -191,6 +268,20 @@ type StopLoadBalancersRequest struct {
 	LoadbalancerIDs []string `json:"loadbalancers,omitempty"`
 }
 
+func (StopLoadBalancersRequest) ActionName() string { return "StopLoadBalancer" }
+
+// Validate implements Validator.
+func (r StopLoadBalancersRequest) Validate() error {
+	return requireIDs(r.ActionName(), "LoadbalancerIDs", r.LoadbalancerIDs)
+}
+
+// StopLoadBalancer sends StopLoadBalancersRequest to anchnet.
+func (c *Client) StopLoadBalancer(ctx context.Context, request *StopLoadBalancersRequest) (*StopLoadBalancersResponse, error) {
+	var response StopLoadBalancersResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type StopLoadBalancersResponse struct {
 	ResponseCommon `json:",inline"`
 	JobID          string `json:"job_id,omitempty"`
@@ -211,6 +302,20 @@ type ModifyLoadBalancerAttributesRequest struct {
 	SecurityGroupID string `json:"security_group_id,omitempty"`
 }
 
+func (ModifyLoadBalancerAttributesRequest) ActionName() string { return "ModifyLoadBalancerAttributes" }
+
+// Validate implements Validator.
+func (r ModifyLoadBalancerAttributesRequest) Validate() error {
+	return requireID(r.ActionName(), "LoadbalancerID", r.LoadbalancerID)
+}
+
+// ModifyLoadBalancerAttributes sends ModifyLoadBalancerAttributesRequest to anchnet.
+func (c *Client) ModifyLoadBalancerAttributes(ctx context.Context, request *ModifyLoadBalancerAttributesRequest) (*ModifyLoadBalancerAttributesResponse, error) {
+	var response ModifyLoadBalancerAttributesResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type ModifyLoadBalancerAttributesResponse struct {
 	ResponseCommon `json:",inline"`
 	JobID          string `json:"job_id,omitempty"`
@@ -225,6 +330,20 @@ type UpdateLoadBalancersRequest struct {
 	LoadbalancerIDs []string `json:"loadbalancers,omitempty"`
 }
 
+func (UpdateLoadBalancersRequest) ActionName() string { return "UpdateLoadBalancers" }
+
+// Validate implements Validator.
+func (r UpdateLoadBalancersRequest) Validate() error {
+	return requireIDs(r.ActionName(), "LoadbalancerIDs", r.LoadbalancerIDs)
+}
+
+// UpdateLoadBalancers sends UpdateLoadBalancersRequest to anchnet.
+func (c *Client) UpdateLoadBalancers(ctx context.Context, request *UpdateLoadBalancersRequest) (*UpdateLoadBalancersResponse, error) {
+	var response UpdateLoadBalancersResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type UpdateLoadBalancersResponse struct {
 	ResponseCommon `json:",inline"`
 	JobID          string `json:"job_id,omitempty"`
@@ -240,6 +359,24 @@ type ResizeLoadBalancersRequest struct {
 	LoadBalancerType LoadBalancerType `json:"loadbalancer_type,omitempty"`
 }
 
+func (ResizeLoadBalancersRequest) ActionName() string { return "ResizeLoadBalancers" }
+
+// Validate implements Validator.
+func (r ResizeLoadBalancersRequest) Validate() error {
+	return firstError(
+		requireIDs(r.ActionName(), "LoadbalancerIDs", r.LoadbalancerIDs),
+		requireOneOf(r.ActionName(), "LoadBalancerType", r.LoadBalancerType,
+			LoadBalancerType20K, LoadBalancerType40K, LoadBalancerType100K),
+	)
+}
+
+// ResizeLoadBalancers sends ResizeLoadBalancersRequest to anchnet.
+func (c *Client) ResizeLoadBalancers(ctx context.Context, request *ResizeLoadBalancersRequest) (*ResizeLoadBalancersResponse, error) {
+	var response ResizeLoadBalancersResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type ResizeLoadBalancersResponse struct {
 	ResponseCommon `json:",inline"`
 	JobID          string `json:"job_id,omitempty"`
@@ -254,6 +391,23 @@ type AssociateEipsToLoadBalancerRequest struct {
 	EipIDs         []string `json:"eips,omitempty"`
 }
 
+func (AssociateEipsToLoadBalancerRequest) ActionName() string { return "AssociateEipsToLoadBalancer" }
+
+// Validate implements Validator.
+func (r AssociateEipsToLoadBalancerRequest) Validate() error {
+	return firstError(
+		requireID(r.ActionName(), "LoadbalancerID", r.LoadbalancerID),
+		requireIDs(r.ActionName(), "EipIDs", r.EipIDs),
+	)
+}
+
+// AssociateEipsToLoadBalancer sends AssociateEipsToLoadBalancerRequest to anchnet.
+func (c *Client) AssociateEipsToLoadBalancer(ctx context.Context, request *AssociateEipsToLoadBalancerRequest) (*AssociateEipsToLoadBalancerResponse, error) {
+	var response AssociateEipsToLoadBalancerResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type AssociateEipsToLoadBalancerResponse struct {
 	ResponseCommon `json:",inline"`
 	JobID          string `json:"job_id,omitempty"`
@@ -268,6 +422,23 @@ type DissociateEipsFromLoadBalancerRequest struct {
 	EipIDs         []string `json:"eips,omitempty"`
 }
 
+func (DissociateEipsFromLoadBalancerRequest) ActionName() string { return "DissociateEipsFromLoadBalancer" }
+
+// Validate implements Validator.
+func (r DissociateEipsFromLoadBalancerRequest) Validate() error {
+	return firstError(
+		requireID(r.ActionName(), "LoadbalancerID", r.LoadbalancerID),
+		requireIDs(r.ActionName(), "EipIDs", r.EipIDs),
+	)
+}
+
+// DissociateEipsFromLoadBalancer sends DissociateEipsFromLoadBalancerRequest to anchnet.
+func (c *Client) DissociateEipsFromLoadBalancer(ctx context.Context, request *DissociateEipsFromLoadBalancerRequest) (*DissociateEipsFromLoadBalancerResponse, error) {
+	var response DissociateEipsFromLoadBalancerResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type DissociateEipsFromLoadBalancerResponse struct {
 	ResponseCommon `json:",inline"`
 	JobID          string `json:"job_id,omitempty"`
@@ -282,6 +453,34 @@ type AddLoadBalancerListenersRequest struct {
 	Listeners      []AddLoadBalancerListenersListener `json:"listeners,omitempty"`
 }
 
+func (AddLoadBalancerListenersRequest) ActionName() string { return "AddLoadBalancerListeners" }
+
+// Validate implements Validator.
+func (r AddLoadBalancerListenersRequest) Validate() error {
+	err := requireID(r.ActionName(), "LoadbalancerID", r.LoadbalancerID)
+	if err == nil && len(r.Listeners) == 0 {
+		err = invalid(r.ActionName(), "Listeners", "is required")
+	}
+	for i, listener := range r.Listeners {
+		if err != nil {
+			break
+		}
+		field := fmt.Sprintf("Listeners[%d]", i)
+		err = firstError(
+			requireRange(r.ActionName(), field+".ListenerPort", listener.ListenerPort, 1, 65535),
+			listener.validate(r.ActionName(), field+"."),
+		)
+	}
+	return err
+}
+
+// AddLoadBalancerListeners sends AddLoadBalancerListenersRequest to anchnet.
+func (c *Client) AddLoadBalancerListeners(ctx context.Context, request *AddLoadBalancerListenersRequest) (*AddLoadBalancerListenersResponse, error) {
+	var response AddLoadBalancerListenersResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type AddLoadBalancerListenersResponse struct {
 	ResponseCommon `json:",inline"`
 	JobID          string   `json:"job_id,omitempty"`
@@ -301,6 +500,20 @@ type DeleteLoadBalancerListenersRequest struct {
 	ListenerIDs   []string `json:"loadbalancer_listeners,omitempty"`
 }
 
+func (DeleteLoadBalancerListenersRequest) ActionName() string { return "DeleteLoadBalancerListeners" }
+
+// Validate implements Validator.
+func (r DeleteLoadBalancerListenersRequest) Validate() error {
+	return requireIDs(r.ActionName(), "ListenerIDs", r.ListenerIDs)
+}
+
+// DeleteLoadBalancerListeners sends DeleteLoadBalancerListenersRequest to anchnet.
+func (c *Client) DeleteLoadBalancerListeners(ctx context.Context, request *DeleteLoadBalancerListenersRequest) (*DeleteLoadBalancerListenersResponse, error) {
+	var response DeleteLoadBalancerListenersResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type DeleteLoadBalancerListenersResponse struct {
 	ResponseCommon `json:",inline"`
 	JobID          string `json:"job_id,omitempty"`
@@ -319,6 +532,15 @@ type DescribeLoadBalancerListenersRequest struct {
 	Limit          int      `json:"limit,omitempty"`
 }
 
+func (DescribeLoadBalancerListenersRequest) ActionName() string { return "DescribeLoadBalancerListeners" }
+
+// DescribeLoadBalancerListeners sends DescribeLoadBalancerListenersRequest to anchnet.
+func (c *Client) DescribeLoadBalancerListeners(ctx context.Context, request *DescribeLoadBalancerListenersRequest) (*DescribeLoadBalancerListenersResponse, error) {
+	var response DescribeLoadBalancerListenersResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type DescribeLoadBalancerListenersResponse struct {
 	ResponseCommon `json:",inline"`
 	ItemSet        []DescribeLoadBalancerListenersItem `json:"item_set,omitempty"`
@@ -329,16 +551,17 @@ type DescribeLoadBalancerListenersItem struct {
 	ListenerID      string                                 `json:"loadbalancer_listener_id,omitempty"`
 	ListenerName    string                                 `json:"loadbalancer_listener_name,omitempty"`
 	Description     string                                 `json:"description,omitempty"`
-	CreateTime      string                                 `json:"create_time,omitempty"`
+	CreateTime      Time                                   `json:"create_time,omitempty"`
 	Disabled        int                                    `json:"disabled"`
 	Backends        []DescribeLoadBalancerListenersBackend `json:"backends,omitempty"`
+	Option          int                                    `json:"option"` // Same as ListenerOption, named differently in responses
 	ListenerOptions `json:",inline"`
 }
 
 type DescribeLoadBalancerListenersBackend struct {
 	ListenerID   string `json:"loadbalancer_listener_id,omitempty"`
 	ListenerName string `json:"loadbalancer_listener_name,omitempty"`
-	CreateTime   string `json:"create_time,omitempty"`
+	CreateTime   Time   `json:"create_time,omitempty"`
 	Port         int    `json:"port,omitempty"`
 	Weight       int    `json:"weight,omitempty"`
 }
@@ -347,11 +570,29 @@ type DescribeLoadBalancerListenersBackend struct {
 // ModifyLoadBalancerListenerAttributes changes attribute of a loadbalancer listener
 //
 type ModifyLoadBalancerListenerAttributesRequest struct {
+	RequestCommon   `json:",inline"`
 	ListenerID      string `json:"loadbalancer_listener_id,omitempty"`
 	ListenerName    string `json:"loadbalancer_listener_name,omitempty"`
 	ListenerOptions `json:",inline"`
 }
 
+func (ModifyLoadBalancerListenerAttributesRequest) ActionName() string { return "ModifyLoadBalancerListenerAttributes" }
+
+// Validate implements Validator.
+func (r ModifyLoadBalancerListenerAttributesRequest) Validate() error {
+	return firstError(
+		requireID(r.ActionName(), "ListenerID", r.ListenerID),
+		r.ListenerOptions.validate(r.ActionName(), ""),
+	)
+}
+
+// ModifyLoadBalancerListenerAttributes sends ModifyLoadBalancerListenerAttributesRequest to anchnet.
+func (c *Client) ModifyLoadBalancerListenerAttributes(ctx context.Context, request *ModifyLoadBalancerListenerAttributesRequest) (*ModifyLoadBalancerListenerAttributesResponse, error) {
+	var response ModifyLoadBalancerListenerAttributesResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type ModifyLoadBalancerListenerAttributesResponse struct {
 	ResponseCommon `json:",inline"`
 	Listener       string `json:"loadbalancer_listener_id,omitempty"`
@@ -367,6 +608,34 @@ type AddLoadBalancerBackendsRequest struct {
 	Backends      []AddLoadBalancerBackendsBackend `json:"backends,omitempty"`
 }
 
+func (AddLoadBalancerBackendsRequest) ActionName() string { return "AddLoadBalancerBackends" }
+
+// Validate implements Validator.
+func (r AddLoadBalancerBackendsRequest) Validate() error {
+	err := requireID(r.ActionName(), "ListenerID", r.ListenerID)
+	if err == nil && len(r.Backends) == 0 {
+		err = invalid(r.ActionName(), "Backends", "is required")
+	}
+	for i, backend := range r.Backends {
+		if err != nil {
+			break
+		}
+		field := fmt.Sprintf("Backends[%d]", i)
+		err = firstError(
+			requireID(r.ActionName(), field+".ResourceID", backend.ResourceID),
+			requireRange(r.ActionName(), field+".Port", backend.Port, 1, 65535),
+		)
+	}
+	return err
+}
+
+// AddLoadBalancerBackends sends AddLoadBalancerBackendsRequest to anchnet.
+func (c *Client) AddLoadBalancerBackends(ctx context.Context, request *AddLoadBalancerBackendsRequest) (*AddLoadBalancerBackendsResponse, error) {
+	var response AddLoadBalancerBackendsResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type AddLoadBalancerBackendsResponse struct {
 	ResponseCommon `json:",inline"`
 	JobID          string   `json:"job_id,omitempty"`
@@ -387,6 +656,20 @@ type DeleteLoadBalancerBackendsRequest struct {
 	BackendIDs    []string `json:"loadbalancer_backends,omitempty"`
 }
 
+func (DeleteLoadBalancerBackendsRequest) ActionName() string { return "DeleteLoadBalancerBackends" }
+
+// Validate implements Validator.
+func (r DeleteLoadBalancerBackendsRequest) Validate() error {
+	return requireIDs(r.ActionName(), "BackendIDs", r.BackendIDs)
+}
+
+// DeleteLoadBalancerBackends sends DeleteLoadBalancerBackendsRequest to anchnet.
+func (c *Client) DeleteLoadBalancerBackends(ctx context.Context, request *DeleteLoadBalancerBackendsRequest) (*DeleteLoadBalancerBackendsResponse, error) {
+	var response DeleteLoadBalancerBackendsResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type DeleteLoadBalancerBackendsResponse struct {
 	ResponseCommon `json:",inline"`
 	JobID          string `json:"job_id,omitempty"`
@@ -407,6 +690,15 @@ type DescribeLoadBalancerBackendsRequest struct {
 	Limit          int      `json:"limit,omitempty"`
 }
 
+func (DescribeLoadBalancerBackendsRequest) ActionName() string { return "DescribeLoadBalancerBackends" }
+
+// DescribeLoadBalancerBackends sends DescribeLoadBalancerBackendsRequest to anchnet.
+func (c *Client) DescribeLoadBalancerBackends(ctx context.Context, request *DescribeLoadBalancerBackendsRequest) (*DescribeLoadBalancerBackendsResponse, error) {
+	var response DescribeLoadBalancerBackendsResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type DescribeLoadBalancerBackendsResponse struct {
 	ResponseCommon `json:",inline"`
 	ItemSet        []DescribeLoadBalancerBackendsItem `json:"item_set,omitempty"`
@@ -416,7 +708,7 @@ type DescribeLoadBalancerBackendsItem struct {
 	BackendID   string                               `json:"loadbalancer_backend_id,omitempty"`
 	BackendName string                               `json:"loadbalancer_backend_name,omitempty"`
 	Description string                               `json:"description,omitempty"`
-	CreateTime  string                               `json:"create_time,omitempty"`
+	CreateTime  Time                                 `json:"create_time,omitempty"`
 	Disabled    int                                  `json:"disabled,omitempty"`
 	Status      BackendStatus                        `json:"status,omitempty"`
 	Port        int                                  `json:"port,omitempty"`
@@ -448,11 +740,26 @@ const (
 // ModifyLoadBalancerBackendAttributes changes attributes of a backend.
 //
 type ModifyLoadBalancerBackendAttributesRequest struct {
-	BackendID string `json:"loadbalancer_backend_id,omitempty"`
-	PolicyID  string `json:"loadbalancer_policy_id,omitempty"`
-	Port      int    `json:"port,omitempty"`
-	Weight    int    `json:"weight,omitempty"`
-	Disabled  int    `json:"disabled,omitempty"`
+	RequestCommon `json:",inline"`
+	BackendID     string `json:"loadbalancer_backend_id,omitempty"`
+	PolicyID      string `json:"loadbalancer_policy_id,omitempty"`
+	Port          int    `json:"port,omitempty"`
+	Weight        int    `json:"weight,omitempty"`
+	Disabled      int    `json:"disabled,omitempty"`
+}
+
+func (ModifyLoadBalancerBackendAttributesRequest) ActionName() string { return "ModifyLoadBalancerBackendAttributes" }
+
+// Validate implements Validator.
+func (r ModifyLoadBalancerBackendAttributesRequest) Validate() error {
+	return requireID(r.ActionName(), "BackendID", r.BackendID)
+}
+
+// ModifyLoadBalancerBackendAttributes sends ModifyLoadBalancerBackendAttributesRequest to anchnet.
+func (c *Client) ModifyLoadBalancerBackendAttributes(ctx context.Context, request *ModifyLoadBalancerBackendAttributesRequest) (*ModifyLoadBalancerBackendAttributesResponse, error) {
+	var response ModifyLoadBalancerBackendAttributesResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
 }
 
 type ModifyLoadBalancerBackendAttributesResponse struct {
@@ -460,3 +767,58 @@ type ModifyLoadBalancerBackendAttributesResponse struct {
 	JobID          string `json:"job_id,omitempty"`
 	BackendID      string `json:"loadbalancer_backend_id,omitempty"`
 }
+
+// WaitLoadBalancerStatus waits until all loadbalancers reach status, e.g. active, listing
+// them with ListAllLoadBalancers on each poll. It returns a *StatusError as soon as any of them
+// becomes suspended, deleted or ceased instead, or is not found. opts can be nil.
+func (c *Client) WaitLoadBalancerStatus(ctx context.Context, ids []string, status LoadBalancerStatus, opts *WaitOptions) error {
+	terminal := []string{string(LoadBalancerStatusSuspended), string(LoadBalancerStatusDeleted), string(LoadBalancerStatusCeased)}
+	return waitStatus(ctx, ids, string(status), terminal, opts, func(ctx context.Context, ids []string) (map[string]string, error) {
+		items, err := c.ListAllLoadBalancers(ctx, DescribeLoadBalancersRequest{LoadbalancerIDs: ids})
+		if err != nil {
+			return nil, err
+		}
+		statuses := make(map[string]string)
+		for _, item := range items {
+			statuses[item.LoadbalancerID] = string(item.Status)
+		}
+		return statuses, nil
+	})
+}
+
+// LoadBalancerPager pages through DescribeLoadBalancers results.
+type LoadBalancerPager struct {
+	pager
+	page []DescribeLoadBalancersItem
+}
+
+// NewLoadBalancerPager creates a pager for loadbalancers matching filter.
+func (c *Client) NewLoadBalancerPager(filter DescribeLoadBalancersRequest) *LoadBalancerPager {
+	p := &LoadBalancerPager{}
+	p.pager = newPager(filter.Offset, filter.Limit, func(ctx context.Context, offset, limit int) (int, int, error) {
+		filter.Offset, filter.Limit = offset, limit
+		p.page = nil
+		response, err := c.DescribeLoadBalancers(ctx, &filter)
+		if err != nil {
+			return 0, 0, err
+		}
+		p.page = response.ItemSet
+		return len(response.ItemSet), response.TotalCount, nil
+	})
+	return p
+}
+
+// Page returns loadbalancers in the page fetched by the last call to Next.
+func (p *LoadBalancerPager) Page() []DescribeLoadBalancersItem {
+	return p.page
+}
+
+// ListAllLoadBalancers returns all loadbalancers matching filter, following all pages.
+func (c *Client) ListAllLoadBalancers(ctx context.Context, filter DescribeLoadBalancersRequest) ([]DescribeLoadBalancersItem, error) {
+	var items []DescribeLoadBalancersItem
+	p := c.NewLoadBalancerPager(filter)
+	for p.Next(ctx) {
+		items = append(items, p.Page()...)
+	}
+	return items, p.Err()
+}
@@ -8,6 +8,9 @@ import (
 	"net/http/httptest"
 	"reflect"
 	"testing"
+	"time"
+
+	"github.com/caicloud/anchnet-go/testutil"
 )
 
 // TestDescribeLoadBalancer tests that we send correct request to describe load balancer.
@@ -23,7 +26,7 @@ func TestDescribeLoadBalancer(t *testing.T) {
 }
 `)
 
-	fakeResponse := RemoveWhitespaces(`
+	fakeResponse := `
 {
   "ret_code": 0,
   "action": "DescribeLoadBalancersResponse",
@@ -79,9 +82,9 @@ func TestDescribeLoadBalancer(t *testing.T) {
   "code": 0,
   "total_count": 2
 }
-`)
+`
 
-	testServer := httptest.NewServer(&FakeHandler{t: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
+	testServer := httptest.NewServer(&testutil.FakeHandler{T: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
 	defer testServer.Close()
 
 	c, err := NewClient(testServer.URL, &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"})
@@ -121,8 +124,8 @@ func TestDescribeLoadBalancer(t *testing.T) {
 				LoadbalancerName: "wang_loanbar",
 				Description:      "51idc",
 				Status:           LoadBalancerStatusActive,
-				StatusTime:       "2015-04-1414:07:08",
-				CreateTime:       "2015-04-1414:06:43",
+				StatusTime:       Time{time.Date(2015, 4, 14, 14, 7, 8, 0, location)},
+				CreateTime:       Time{time.Date(2015, 4, 14, 14, 6, 43, 0, location)},
 				LoadbalancerType: LoadBalancerType20K,
 				Listeners:        []DescribeLoadBalancersListener{},
 				Eips:             []DescribeLoadBalancersEIP{},
@@ -140,8 +143,8 @@ func TestDescribeLoadBalancer(t *testing.T) {
 				LoadbalancerName: "yy",
 				Description:      "51idc",
 				Status:           LoadBalancerStatusActive,
-				StatusTime:       "2015-04-1616:39:28",
-				CreateTime:       "2015-04-1616:39:05",
+				StatusTime:       Time{time.Date(2015, 4, 16, 16, 39, 28, 0, location)},
+				CreateTime:       Time{time.Date(2015, 4, 16, 16, 39, 5, 0, location)},
 				LoadbalancerType: LoadBalancerType20K,
 				Listeners:        []DescribeLoadBalancersListener{},
 				Eips: []DescribeLoadBalancersEIP{
@@ -200,7 +203,7 @@ func TestCreateLoadBalancer(t *testing.T) {
 }
 `)
 
-	testServer := httptest.NewServer(&FakeHandler{t: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
+	testServer := httptest.NewServer(&testutil.FakeHandler{T: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
 	defer testServer.Close()
 
 	c, err := NewClient(testServer.URL, &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"})
@@ -267,7 +270,7 @@ func TestDeleteLoadBalancers(t *testing.T) {
 }
 `)
 
-	testServer := httptest.NewServer(&FakeHandler{t: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
+	testServer := httptest.NewServer(&testutil.FakeHandler{T: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
 	defer testServer.Close()
 
 	c, err := NewClient(testServer.URL, &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"})
@@ -323,7 +326,7 @@ func TestModifyLoadBalancerAttributes(t *testing.T) {
 }
 `)
 
-	testServer := httptest.NewServer(&FakeHandler{t: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
+	testServer := httptest.NewServer(&testutil.FakeHandler{T: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
 	defer testServer.Close()
 
 	c, err := NewClient(testServer.URL, &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"})
@@ -391,7 +394,7 @@ func TestAddLoadBalancerListeners(t *testing.T) {
 }
 `)
 
-	testServer := httptest.NewServer(&FakeHandler{t: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
+	testServer := httptest.NewServer(&testutil.FakeHandler{T: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
 	defer testServer.Close()
 
 	c, err := NewClient(testServer.URL, &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"})
@@ -460,7 +463,7 @@ func TestDeleteLoadBalancerListeners(t *testing.T) {
 }
 `)
 
-	testServer := httptest.NewServer(&FakeHandler{t: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
+	testServer := httptest.NewServer(&testutil.FakeHandler{T: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
 	defer testServer.Close()
 
 	c, err := NewClient(testServer.URL, &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"})
@@ -503,7 +506,7 @@ func TestDescribeLoadBalancerListeners(t *testing.T) {
 }
 `)
 
-	fakeResponse := RemoveWhitespaces(`
+	fakeResponse := `
 {
   "ret_code": 0,
   "action": "DescribeLoadBalancerListenersResponse",
@@ -537,9 +540,9 @@ func TestDescribeLoadBalancerListeners(t *testing.T) {
   ],
   "code": 0
 }
-`)
+`
 
-	testServer := httptest.NewServer(&FakeHandler{t: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
+	testServer := httptest.NewServer(&testutil.FakeHandler{T: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
 	defer testServer.Close()
 
 	c, err := NewClient(testServer.URL, &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"})
@@ -569,7 +572,7 @@ func TestDescribeLoadBalancerListeners(t *testing.T) {
 				LoadbalancerID: "lb-TBA0YUMM",
 				ListenerID:     "lbl-SV2DLPI3",
 				ListenerName:   "yy",
-				CreateTime:     "2015-04-1613:43:34",
+				CreateTime:     Time{time.Date(2015, 4, 16, 13, 43, 34, 0, location)},
 				Description:    "51idc",
 				Disabled:       0,
 				ListenerOptions: ListenerOptions{
@@ -586,7 +589,7 @@ func TestDescribeLoadBalancerListeners(t *testing.T) {
 					{
 						ListenerID:   "lbb-A19KZ5KU",
 						ListenerName: "yy",
-						CreateTime:   "2015-04-1613:46:39",
+						CreateTime:   Time{time.Date(2015, 4, 16, 13, 46, 39, 0, location)},
 						Port:         8080,
 						Weight:       1,
 					},
@@ -627,7 +630,7 @@ func TestAddLoadBalancerBackends(t *testing.T) {
 }
 `)
 
-	testServer := httptest.NewServer(&FakeHandler{t: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
+	testServer := httptest.NewServer(&testutil.FakeHandler{T: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
 	defer testServer.Close()
 
 	c, err := NewClient(testServer.URL, &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"})
@@ -677,7 +680,7 @@ func TestDescribeLoadBalancerBackends(t *testing.T) {
 }
 `)
 
-	fakeResponse := RemoveWhitespaces(`
+	fakeResponse := `
 {
   "ret_code": 0,
   "action": "DescribeLoadBalancerBackendsResponse",
@@ -703,9 +706,9 @@ func TestDescribeLoadBalancerBackends(t *testing.T) {
   ],
   "code": 0
 }
-`)
+`
 
-	testServer := httptest.NewServer(&FakeHandler{t: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
+	testServer := httptest.NewServer(&testutil.FakeHandler{T: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
 	defer testServer.Close()
 
 	c, err := NewClient(testServer.URL, &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"})
@@ -740,7 +743,7 @@ func TestDescribeLoadBalancerBackends(t *testing.T) {
 				ResourceID:  "i-SW55FS5W",
 				Status:      BackendStatusDown,
 				Weight:      1,
-				CreateTime:  "2015-04-1615:07:43",
+				CreateTime:  Time{time.Date(2015, 4, 16, 15, 7, 43, 0, location)},
 				ListenerID:  "lbl-OKI5C36Z",
 				Resource: DescribeLoadBalancerBackendsResource{
 					ResourceID:   "i-SW55FS5W",
@@ -0,0 +1,49 @@
+// Copyright 2015 anchnet-go authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package anchnet
+
+import (
+	"github.com/caicloud/anchnet-go/redact"
+)
+
+// Logger logs debug messages of Client, see Client.Logger. *log.Logger implements
+// Logger.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// Redacted replaces values of secret fields in debug logs.
+const Redacted = redact.Redacted
+
+// RedactedFields are json fields whose values are secrets, e.g. API token or login
+// password, and are never logged. It is the same map as redact.Fields.
+var RedactedFields = redact.Fields
+
+// Redact returns json body with values of RedactedFields replaced by Redacted, see
+// redact.JSON.
+func Redact(body []byte) []byte {
+	return redact.JSON(body)
+}
+
+// logMiddleware logs every call, with secrets redacted. The signature header is
+// not logged.
+func logMiddleware(logger Logger) Middleware {
+	return func(next Handler) Handler {
+		return func(call *Call) error {
+			logger.Printf("anchnet: action=%v attempt=%d request=%s", call.Action, call.Attempt, Redact(call.RequestBody))
+			err := next(call)
+			status := 0
+			if call.HTTPResponse != nil {
+				status = call.HTTPResponse.StatusCode
+			}
+			if err != nil {
+				logger.Printf("anchnet: action=%v attempt=%d status=%d response=%s error=%q", call.Action, call.Attempt, status, Redact(call.ResponseBody), err)
+			} else {
+				logger.Printf("anchnet: action=%v attempt=%d status=%d response=%s", call.Action, call.Attempt, status, Redact(call.ResponseBody))
+			}
+			return err
+		}
+	}
+}
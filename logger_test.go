@@ -0,0 +1,70 @@
+// Copyright 2015 anchnet-go authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package anchnet
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/caicloud/anchnet-go/testutil"
+)
+
+type bufferLogger struct {
+	bytes.Buffer
+}
+
+func (l *bufferLogger) Printf(format string, v ...interface{}) {
+	fmt.Fprintf(&l.Buffer, format+"\n", v...)
+}
+
+// TestLogger tests that client logs requests and responses without secrets.
+func TestLogger(t *testing.T) {
+	expectedJson := `{"action":"ResetLoginPasswd","token":"E5I9QKJF1O2B5PXE68LG","zone":"ac1","instances":["i-FF830WKU"],"login_passwd":"caicloud2015ABC"}`
+	fakeResponse := `{"ret_code":0,"action":"ResetLoginPasswdResponse","code":0,"job_id":"job-3KZ5NHZH"}`
+	testServer := httptest.NewServer(&testutil.FakeHandler{T: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
+	defer testServer.Close()
+
+	c, err := NewClient(testServer.URL, &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"})
+	if err != nil {
+		t.Errorf("Unexpected non-nil error %v", err)
+	}
+	logger := &bufferLogger{}
+	c.Logger = logger
+
+	var response ResetLoginPasswdResponse
+	request := ResetLoginPasswdRequest{InstanceIDs: []string{"i-FF830WKU"}, LoginPasswd: "caicloud2015ABC"}
+	if err := c.SendRequest(request, &response); err != nil {
+		t.Errorf("Unexpected non-nil error %v", err)
+	}
+
+	expected := `anchnet: action=ResetLoginPasswd attempt=1 request={"action":"ResetLoginPasswd","instances":["i-FF830WKU"],"login_passwd":"REDACTED","token":"REDACTED","zone":"ac1"}
+anchnet: action=ResetLoginPasswd attempt=1 status=200 response={"action":"ResetLoginPasswdResponse","code":0,"job_id":"job-3KZ5NHZH","ret_code":0}
+`
+	if logger.String() != expected {
+		t.Errorf("Expected log \n%v, got \n%v", expected, logger.String())
+	}
+	for _, secret := range []string{"E5I9QKJF1O2B5PXE68LG", "caicloud2015ABC"} {
+		if strings.Contains(logger.String(), secret) {
+			t.Errorf("Expected %v redacted, got %v", secret, logger.String())
+		}
+	}
+
+	// Errors are logged with status.
+	testServer.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad gateway", http.StatusBadGateway)
+	})
+	c.RetryPolicy = NoRetry
+	logger.Reset()
+	if err := c.SendRequest(request, &response); err == nil {
+		t.Errorf("Unexpected nil error")
+	}
+	if !strings.Contains(logger.String(), `status=502 response=bad gateway`) {
+		t.Errorf("Expected error logged, got %v", logger.String())
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright 2015 anchnet-go authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package anchnet
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// MetricsCollector collects metrics of requests sent by Client, see Client.Metrics.
+// Implementations must be safe for concurrent use.
+type MetricsCollector interface {
+	// ObserveRequest is called once per request, after its last attempt, with the
+	// total latency including retries, and the error returned to the caller.
+	ObserveRequest(action string, latency time.Duration, err error)
+	// ObserveRetry is called before a failed attempt is retried, with its error.
+	ObserveRetry(action string, err error)
+}
+
+// DefaultLatencyBuckets are upper bounds of latency histogram buckets of new
+// Metrics, in seconds.
+var DefaultLatencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// ErrorCodeLabel returns the label of err in metrics: its anchnet error code, e.g.
+// "2100", "http_<status>" if anchnet returned an http error, or "client" for other
+// errors, e.g. network failures.
+func ErrorCodeLabel(err error) string {
+	if apiErr, ok := IsAPIError(err); ok {
+		if apiErr.Code != 0 {
+			return strconv.Itoa(apiErr.Code)
+		}
+		return fmt.Sprintf("http_%d", apiErr.HTTPStatus)
+	}
+	return "client"
+}
+
+// ActionMetrics are metrics of requests of one action.
+type ActionMetrics struct {
+	Requests int64
+	Retries  int64
+	// Errors counts failed requests by ErrorCodeLabel.
+	Errors map[string]int64
+	// Buckets counts requests by latency: Buckets[i] is the number of requests not
+	// slower than the i-th bucket of Metrics. Counts are not cumulative; the last
+	// one counts requests slower than all buckets.
+	Buckets    []int64
+	LatencySum time.Duration
+}
+
+// Metrics is the in-memory MetricsCollector. It can be read with Action, or written
+// in prometheus text exposition format, e.g. to be scraped as an http.Handler.
+type Metrics struct {
+	buckets []float64
+
+	mu      sync.Mutex
+	actions map[string]*ActionMetrics
+}
+
+// NewMetrics returns empty metrics with given latency buckets, in seconds and in
+// increasing order. DefaultLatencyBuckets is used if none is given.
+func NewMetrics(buckets ...float64) *Metrics {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	return &Metrics{buckets: buckets, actions: make(map[string]*ActionMetrics)}
+}
+
+// action returns metrics of action, creating them if needed. m.mu must be held.
+func (m *Metrics) action(action string) *ActionMetrics {
+	a, ok := m.actions[action]
+	if !ok {
+		a = &ActionMetrics{Errors: make(map[string]int64), Buckets: make([]int64, len(m.buckets)+1)}
+		m.actions[action] = a
+	}
+	return a
+}
+
+// ObserveRequest implements MetricsCollector.
+func (m *Metrics) ObserveRequest(action string, latency time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	a := m.action(action)
+	a.Requests++
+	a.LatencySum += latency
+	a.Buckets[sort.SearchFloat64s(m.buckets, latency.Seconds())]++
+	if err != nil {
+		a.Errors[ErrorCodeLabel(err)]++
+	}
+}
+
+// ObserveRetry implements MetricsCollector.
+func (m *Metrics) ObserveRetry(action string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.action(action).Retries++
+}
+
+// Action returns a copy of metrics of action.
+func (m *Metrics) Action(action string) ActionMetrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	a := ActionMetrics{Errors: make(map[string]int64), Buckets: make([]int64, len(m.buckets)+1)}
+	if src, ok := m.actions[action]; ok {
+		a.Requests, a.Retries, a.LatencySum = src.Requests, src.Retries, src.LatencySum
+		copy(a.Buckets, src.Buckets)
+		for code, count := range src.Errors {
+			a.Errors[code] = count
+		}
+	}
+	return a
+}
+
+// WriteText writes metrics in prometheus text exposition format, sorted by action.
+func (m *Metrics) WriteText(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var actions []string
+	for action := range m.actions {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+
+	b := bufio.NewWriter(w)
+	fmt.Fprintln(b, "# HELP anchnet_requests_total Number of anchnet API requests.")
+	fmt.Fprintln(b, "# TYPE anchnet_requests_total counter")
+	for _, action := range actions {
+		fmt.Fprintf(b, "anchnet_requests_total{action=%q} %d\n", action, m.actions[action].Requests)
+	}
+	fmt.Fprintln(b, "# HELP anchnet_request_errors_total Number of failed anchnet API requests, by error code.")
+	fmt.Fprintln(b, "# TYPE anchnet_request_errors_total counter")
+	for _, action := range actions {
+		errs := m.actions[action].Errors
+		var codes []string
+		for code := range errs {
+			codes = append(codes, code)
+		}
+		sort.Strings(codes)
+		for _, code := range codes {
+			fmt.Fprintf(b, "anchnet_request_errors_total{action=%q,code=%q} %d\n", action, code, errs[code])
+		}
+	}
+	fmt.Fprintln(b, "# HELP anchnet_request_retries_total Number of retried anchnet API request attempts.")
+	fmt.Fprintln(b, "# TYPE anchnet_request_retries_total counter")
+	for _, action := range actions {
+		fmt.Fprintf(b, "anchnet_request_retries_total{action=%q} %d\n", action, m.actions[action].Retries)
+	}
+	fmt.Fprintln(b, "# HELP anchnet_request_duration_seconds Latency of anchnet API requests, including retries.")
+	fmt.Fprintln(b, "# TYPE anchnet_request_duration_seconds histogram")
+	for _, action := range actions {
+		a := m.actions[action]
+		var count int64
+		for i, bound := range m.buckets {
+			count += a.Buckets[i]
+			fmt.Fprintf(b, "anchnet_request_duration_seconds_bucket{action=%q,le=%q} %d\n", action, strconv.FormatFloat(bound, 'g', -1, 64), count)
+		}
+		fmt.Fprintf(b, "anchnet_request_duration_seconds_bucket{action=%q,le=\"+Inf\"} %d\n", action, a.Requests)
+		fmt.Fprintf(b, "anchnet_request_duration_seconds_sum{action=%q} %v\n", action, strconv.FormatFloat(a.LatencySum.Seconds(), 'g', -1, 64))
+		fmt.Fprintf(b, "anchnet_request_duration_seconds_count{action=%q} %d\n", action, a.Requests)
+	}
+	return b.Flush()
+}
+
+// ServeHTTP writes metrics in prometheus text exposition format.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	m.WriteText(w)
+}
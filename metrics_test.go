@@ -0,0 +1,106 @@
+// Copyright 2015 anchnet-go authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package anchnet
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestClientMetrics tests that client reports requests, retries and errors.
+func TestClientMetrics(t *testing.T) {
+	handler := &flakyHandler{
+		failures: 3,
+		status:   http.StatusOK,
+		body:     `{"code": 5100, "ret_code": 5100, "message": "busy"}`,
+		response: `{"ret_code": 0, "code": 0, "total_count": 0}`,
+	}
+	testServer := httptest.NewServer(handler)
+	defer testServer.Close()
+
+	c, err := NewClient(testServer.URL, &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"})
+	if err != nil {
+		t.Errorf("Unexpected non-nil error %v", err)
+	}
+	c.RetryPolicy = RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}
+	metrics := NewMetrics()
+	c.Metrics = metrics
+
+	var response DescribeJobsResponse
+	// Fails twice, then succeeds at the second attempt.
+	if err := c.SendRequest(DescribeJobsRequest{}, &response); err == nil {
+		t.Errorf("Unexpected nil error")
+	}
+	if err := c.SendRequest(DescribeJobsRequest{}, &response); err != nil {
+		t.Errorf("Unexpected non-nil error %v", err)
+	}
+
+	a := metrics.Action("DescribeJobs")
+	if a.Requests != 2 || a.Retries != 2 || !reflect.DeepEqual(map[string]int64{"5100": 1}, a.Errors) {
+		t.Errorf("Unexpected metrics %+v", a)
+	}
+	var count int64
+	for _, c := range a.Buckets {
+		count += c
+	}
+	if count != 2 || a.LatencySum <= 0 {
+		t.Errorf("Unexpected latency metrics %+v", a)
+	}
+}
+
+// TestMetricsText tests prometheus text exposition of metrics.
+func TestMetricsText(t *testing.T) {
+	metrics := NewMetrics(0.1, 1)
+	metrics.ObserveRequest("RunInstances", 50*time.Millisecond, nil)
+	metrics.ObserveRequest("RunInstances", 2*time.Second, &APIError{Code: ErrorCodeQuotaExceeded})
+	metrics.ObserveRetry("DescribeInstances", &APIError{HTTPStatus: http.StatusBadGateway})
+	metrics.ObserveRequest("DescribeInstances", 500*time.Millisecond, &APIError{HTTPStatus: http.StatusBadGateway})
+	metrics.ObserveRequest("DescribeInstances", time.Second, errors.New("connection refused"))
+
+	expected := `# HELP anchnet_requests_total Number of anchnet API requests.
+# TYPE anchnet_requests_total counter
+anchnet_requests_total{action="DescribeInstances"} 2
+anchnet_requests_total{action="RunInstances"} 2
+# HELP anchnet_request_errors_total Number of failed anchnet API requests, by error code.
+# TYPE anchnet_request_errors_total counter
+anchnet_request_errors_total{action="DescribeInstances",code="client"} 1
+anchnet_request_errors_total{action="DescribeInstances",code="http_502"} 1
+anchnet_request_errors_total{action="RunInstances",code="2500"} 1
+# HELP anchnet_request_retries_total Number of retried anchnet API request attempts.
+# TYPE anchnet_request_retries_total counter
+anchnet_request_retries_total{action="DescribeInstances"} 1
+anchnet_request_retries_total{action="RunInstances"} 0
+# HELP anchnet_request_duration_seconds Latency of anchnet API requests, including retries.
+# TYPE anchnet_request_duration_seconds histogram
+anchnet_request_duration_seconds_bucket{action="DescribeInstances",le="0.1"} 0
+anchnet_request_duration_seconds_bucket{action="DescribeInstances",le="1"} 2
+anchnet_request_duration_seconds_bucket{action="DescribeInstances",le="+Inf"} 2
+anchnet_request_duration_seconds_sum{action="DescribeInstances"} 1.5
+anchnet_request_duration_seconds_count{action="DescribeInstances"} 2
+anchnet_request_duration_seconds_bucket{action="RunInstances",le="0.1"} 1
+anchnet_request_duration_seconds_bucket{action="RunInstances",le="1"} 1
+anchnet_request_duration_seconds_bucket{action="RunInstances",le="+Inf"} 2
+anchnet_request_duration_seconds_sum{action="RunInstances"} 2.05
+anchnet_request_duration_seconds_count{action="RunInstances"} 2
+`
+	var buf bytes.Buffer
+	if err := metrics.WriteText(&buf); err != nil {
+		t.Errorf("Unexpected non-nil error %v", err)
+	}
+	if buf.String() != expected {
+		t.Errorf("Expected metrics \n%v, got \n%v", expected, buf.String())
+	}
+
+	recorder := httptest.NewRecorder()
+	metrics.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	if recorder.Body.String() != expected {
+		t.Errorf("Expected served metrics \n%v, got \n%v", expected, recorder.Body.String())
+	}
+}
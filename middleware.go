@@ -0,0 +1,62 @@
+// Copyright 2015 anchnet-go authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package anchnet
+
+import (
+	"net/http"
+)
+
+// Call is a single attempt to send a request to anchnet, as seen by middlewares.
+// Retried requests make one Call per attempt.
+type Call struct {
+	// Action is the anchnet API called, e.g. RunInstances.
+	Action string
+	// Request is a pointer to a copy of the typed request, with RequestCommon set.
+	Request Request
+	// Attempt is the number of the attempt, starting from 1.
+	Attempt int
+	// HTTPRequest is the signed http request. Middlewares can add headers, e.g. for
+	// tracing, but changing the body breaks the signature.
+	HTTPRequest *http.Request
+	// RequestBody is the json body of HTTPRequest.
+	RequestBody []byte
+
+	// Set when the call returns. HTTPResponse and ResponseBody are nil if no
+	// response was received; the body of HTTPResponse is already read and closed.
+	HTTPResponse *http.Response
+	ResponseBody []byte
+	// Response is a pointer to the typed response, decoded from ResponseBody.
+	Response interface{}
+}
+
+// Handler sends a call, returning the same error as SendRequest would.
+type Handler func(call *Call) error
+
+// Middleware wraps a Handler, to act before and after the call is sent, e.g.:
+//   func(next anchnet.Handler) anchnet.Handler {
+//     return func(call *anchnet.Call) error {
+//       call.HTTPRequest.Header.Set("X-Trace-Id", traceID)
+//       err := next(call)
+//       log.Printf("%v: %s, %v", call.Action, call.ResponseBody, err)
+//       return err
+//     }
+//   }
+// A middleware can also return without calling next, e.g. to serve a cached
+// response.
+type Middleware func(next Handler) Handler
+
+// Use adds middlewares to the client. Middlewares are called in the order they
+// are added: the first one sees the call first, and its result last.
+func (c *Client) Use(middlewares ...Middleware) {
+	c.middlewares = append(c.middlewares, middlewares...)
+}
+
+// chain returns handler wrapped by middlewares, the first being outermost.
+func chain(middlewares []Middleware, handler Handler) Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
@@ -0,0 +1,97 @@
+// Copyright 2015 anchnet-go authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package anchnet
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestMiddleware tests that middlewares see every attempt in order, and can change
+// http request headers and short-circuit calls.
+func TestMiddleware(t *testing.T) {
+	handler := &flakyHandler{
+		failures: 1,
+		status:   http.StatusServiceUnavailable,
+		body:     "busy",
+		response: `{"ret_code": 0, "code": 0, "total_count": 1}`,
+	}
+	var traceIDs []string
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceIDs = append(traceIDs, r.Header.Get("X-Trace-Id"))
+		handler.ServeHTTP(w, r)
+	}))
+	defer testServer.Close()
+
+	c, err := NewClient(testServer.URL, &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"})
+	if err != nil {
+		t.Errorf("Unexpected non-nil error %v", err)
+	}
+	c.RetryPolicy = RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}
+
+	var events []string
+	c.Use(func(next Handler) Handler {
+		return func(call *Call) error {
+			call.HTTPRequest.Header.Set("X-Trace-Id", fmt.Sprintf("trace-%d", call.Attempt))
+			events = append(events, "outer before")
+			err := next(call)
+			events = append(events, "outer after")
+			return err
+		}
+	}, func(next Handler) Handler {
+		return func(call *Call) error {
+			request := call.Request.(*DescribeJobsRequest)
+			events = append(events, fmt.Sprintf("inner before %v %v %v", call.Action, request.JobIDs, request.Token))
+			err := next(call)
+			count := call.Response.(*DescribeJobsResponse).TotalCount
+			events = append(events, fmt.Sprintf("inner after %d %s %d %v", call.HTTPResponse.StatusCode, call.ResponseBody, count, err != nil))
+			return err
+		}
+	})
+
+	var response DescribeJobsResponse
+	if err := c.SendRequest(DescribeJobsRequest{JobIDs: []string{"job-ZUBILH5I"}}, &response); err != nil {
+		t.Errorf("Unexpected non-nil error %v", err)
+	}
+	expectedEvents := []string{
+		"outer before",
+		"inner before DescribeJobs [job-ZUBILH5I] E5I9QKJF1O2B5PXE68LG",
+		`inner after 503 busy 0 true`,
+		"outer after",
+		"outer before",
+		"inner before DescribeJobs [job-ZUBILH5I] E5I9QKJF1O2B5PXE68LG",
+		`inner after 200 {"ret_code": 0, "code": 0, "total_count": 1} 1 false`,
+		"outer after",
+	}
+	if !reflect.DeepEqual(expectedEvents, events) {
+		t.Errorf("Expected events \n%q, got \n%q", expectedEvents, events)
+	}
+	if expected := []string{"trace-1", "trace-2"}; !reflect.DeepEqual(expected, traceIDs) {
+		t.Errorf("Expected trace IDs %v, got %v", expected, traceIDs)
+	}
+
+	// A middleware can answer without sending the request.
+	cached := errors.New("cached")
+	c, err = NewClient(testServer.URL, &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"})
+	if err != nil {
+		t.Errorf("Unexpected non-nil error %v", err)
+	}
+	c.Use(func(next Handler) Handler {
+		return func(call *Call) error {
+			return cached
+		}
+	})
+	if err := c.SendRequest(DescribeJobsRequest{JobIDs: []string{"job-ZUBILH5I"}}, &response); err != cached {
+		t.Errorf("Expected error %v, got %v", cached, err)
+	}
+	if len(traceIDs) != 2 {
+		t.Errorf("Expected no more requests, got %v", traceIDs)
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright 2015 anchnet-go authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package anchnet
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// anchnet is not consistent about numbers in responses: the same field can be a
+// JSON number in one API and a string in another, e.g. volume size is "10" in
+// DescribeVolumes. Types below accept both representations, so decoding doesn't
+// break when anchnet changes one of them.
+
+// Int is an integer which can be decoded from a JSON number or string. Empty
+// string is decoded as 0.
+type Int int
+
+// UnmarshalJSON decodes a number or a string containing a number.
+func (i *Int) UnmarshalJSON(data []byte) error {
+	var value int
+	if err := unmarshalNumber(data, &value); err != nil {
+		return fmt.Errorf("anchnet int should be a number, got %s", data)
+	}
+	*i = Int(value)
+	return nil
+}
+
+// Float64 is a float which can be decoded from a JSON number or string. Empty
+// string is decoded as 0.
+type Float64 float64
+
+// UnmarshalJSON decodes a number or a string containing a number.
+func (f *Float64) UnmarshalJSON(data []byte) error {
+	var value float64
+	if err := unmarshalNumber(data, &value); err != nil {
+		return fmt.Errorf("anchnet float should be a number, got %s", data)
+	}
+	*f = Float64(value)
+	return nil
+}
+
+// unmarshalNumber decodes data into v, a pointer to a number, unquoting data first
+// if it's a string. null leaves v unchanged.
+func unmarshalNumber(data []byte, v interface{}) error {
+	if bytes.HasPrefix(data, []byte(`"`)) {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		if s == "" {
+			return nil
+		}
+		data = []byte(s)
+	}
+	return json.Unmarshal(data, v)
+}
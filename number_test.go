@@ -0,0 +1,58 @@
+// Copyright 2015 anchnet-go authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package anchnet
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// TestNumber tests that numbers are decoded from both JSON numbers and strings.
+func TestNumber(t *testing.T) {
+	tests := []struct {
+		data     string
+		expected DescribeVolumesItem
+	}{
+		{`{"size": 10, "volume_type": 1}`, DescribeVolumesItem{Size: 10, VolumeType: VolumeTypeCapacity}},
+		{`{"size": "10", "volume_type": "1"}`, DescribeVolumesItem{Size: 10, VolumeType: VolumeTypeCapacity}},
+		{`{"size": "", "volume_type": 0}`, DescribeVolumesItem{VolumeType: VolumeTypePerformance}},
+		{`{"size": 10, "volume_type": ""}`, DescribeVolumesItem{Size: 10}},
+		{`{"size": null}`, DescribeVolumesItem{}},
+	}
+	for i, test := range tests {
+		var actual DescribeVolumesItem
+		if err := json.Unmarshal([]byte(test.data), &actual); err != nil {
+			t.Errorf("Test %d: unexpected non-nil error %v", i, err)
+		}
+		if !reflect.DeepEqual(test.expected, actual) {
+			t.Errorf("Test %d: expected \n%+v, got \n%+v", i, test.expected, actual)
+		}
+	}
+
+	var hd RunInstancesHardDisk
+	if err := json.Unmarshal([]byte(`{"type": "1", "unit": 10}`), &hd); err != nil || hd.Type != HDTypeCapacity {
+		t.Errorf("Expected capacity disk, got %v, error %v", hd.Type, err)
+	}
+	// Empty disk type is not taken for a performance disk.
+	if err := json.Unmarshal([]byte(`{"type": "", "unit": 10}`), &hd); err == nil {
+		t.Errorf("Expected error for empty disk type, got %v", hd.Type)
+	}
+
+	var balance DescribeProjectsBalance
+	if err := json.Unmarshal([]byte(`{"value": "100.50", "coupon": 20, "consume": "0.5"}`), &balance); err != nil {
+		t.Errorf("Unexpected non-nil error %v", err)
+	}
+	if expected := (DescribeProjectsBalance{Value: 100.5, Coupon: 20, Consume: 0.5}); balance != expected {
+		t.Errorf("Expected balance %+v, got %+v", expected, balance)
+	}
+
+	for _, data := range []string{`{"size": "10GB"}`, `{"size": true}`, `{"volume_type": "ssd"}`} {
+		var item DescribeVolumesItem
+		if err := json.Unmarshal([]byte(data), &item); err == nil {
+			t.Errorf("Expected error decoding %v", data)
+		}
+	}
+}
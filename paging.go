@@ -0,0 +1,70 @@
+// Copyright 2015 anchnet-go authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package anchnet
+
+import (
+	"context"
+)
+
+// DefaultPageSize is the number of items requested per page by pagers, unless
+// Limit is set in the filter.
+const DefaultPageSize = 50
+
+// pager walks through pages of a Describe* action. It is embedded in typed
+// pagers, e.g. InstancePager, which keep the items of the current page. Offset
+// and Limit of the filter given to a typed pager set the first item and page
+// size. All typed pagers are used the same way:
+//   pager := client.NewInstancePager(filter)
+//   for pager.Next(ctx) {
+//     for _, item := range pager.Page() { ... }
+//   }
+//   if err := pager.Err(); err != nil { ... }
+type pager struct {
+	offset int
+	limit  int
+	done   bool
+	err    error
+	// fetch requests a page, and returns the number of items in the page along
+	// with total_count reported by anchnet.
+	fetch func(ctx context.Context, offset, limit int) (count, total int, err error)
+}
+
+func newPager(offset, limit int, fetch func(ctx context.Context, offset, limit int) (int, int, error)) pager {
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	return pager{offset: offset, limit: limit, fetch: fetch}
+}
+
+// Next fetches the next page. It returns false when there are no more pages, or
+// an error occurs, see Err.
+func (p *pager) Next(ctx context.Context) bool {
+	if p.done {
+		return false
+	}
+	count, total, err := p.fetch(ctx, p.offset, p.limit)
+	if err != nil {
+		p.err = err
+		p.done = true
+		return false
+	}
+	if count == 0 {
+		p.done = true
+		return false
+	}
+	p.offset += count
+	// total_count alone can't be trusted: it is omitted by some actions and may
+	// be under-reported. A full page means there may be more items; a short page
+	// ends the walk, unless total_count says otherwise (the server may cap limit).
+	if count < p.limit && p.offset >= total {
+		p.done = true
+	}
+	return true
+}
+
+// Err returns the error, if any, that stopped the pager.
+func (p *pager) Err() error {
+	return p.err
+}
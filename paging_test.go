@@ -0,0 +1,156 @@
+// Copyright 2015 anchnet-go authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package anchnet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// fakePagingHandler serves DescribeInstances over 'items' instances, returning at
+// most 'maxLimit' items per page, and reporting total count with 'total'.
+type fakePagingHandler struct {
+	items    int
+	maxLimit int
+	total    func(items int) int
+
+	requests int
+}
+
+func (f *fakePagingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.requests++
+	body, _ := ioutil.ReadAll(r.Body)
+	var request DescribeInstancesRequest
+	json.Unmarshal(body, &request)
+
+	limit := request.Limit
+	if f.maxLimit > 0 && limit > f.maxLimit {
+		limit = f.maxLimit
+	}
+	var response DescribeInstancesResponse
+	for i := request.Offset; i < f.items && i < request.Offset+limit; i++ {
+		response.ItemSet = append(response.ItemSet, DescribeInstancesItem{InstanceID: fmt.Sprintf("i-%d", i), Status: InstanceStatusRunning})
+	}
+	response.TotalCount = f.total(f.items)
+	json.NewEncoder(w).Encode(response)
+}
+
+// TestListAllInstances tests that pagers walk all pages, even if anchnet
+// misreports total count.
+func TestListAllInstances(t *testing.T) {
+	tests := []struct {
+		name             string
+		handler          *fakePagingHandler
+		filter           DescribeInstancesRequest
+		expectedItems    int
+		expectedRequests int
+	}{
+		{
+			name:             "total count reported",
+			handler:          &fakePagingHandler{items: 25, total: func(n int) int { return n }},
+			filter:           DescribeInstancesRequest{Limit: 10},
+			expectedItems:    25,
+			expectedRequests: 3,
+		},
+		{
+			name:             "exact multiple of page size",
+			handler:          &fakePagingHandler{items: 20, total: func(n int) int { return n }},
+			filter:           DescribeInstancesRequest{Limit: 10},
+			expectedItems:    20,
+			expectedRequests: 3,
+		},
+		{
+			name:             "total count omitted",
+			handler:          &fakePagingHandler{items: 25, total: func(n int) int { return 0 }},
+			filter:           DescribeInstancesRequest{Limit: 10},
+			expectedItems:    25,
+			expectedRequests: 3,
+		},
+		{
+			name:             "total count under-reported",
+			handler:          &fakePagingHandler{items: 25, total: func(n int) int { return 10 }},
+			filter:           DescribeInstancesRequest{Limit: 10},
+			expectedItems:    25,
+			expectedRequests: 3,
+		},
+		{
+			name:             "server caps page size",
+			handler:          &fakePagingHandler{items: 25, maxLimit: 5, total: func(n int) int { return n }},
+			filter:           DescribeInstancesRequest{Limit: 10},
+			expectedItems:    25,
+			expectedRequests: 5,
+		},
+		{
+			name:             "start from offset with default page size",
+			handler:          &fakePagingHandler{items: 120, total: func(n int) int { return n }},
+			filter:           DescribeInstancesRequest{Offset: 30},
+			expectedItems:    90,
+			expectedRequests: 2,
+		},
+		{
+			name:             "no items",
+			handler:          &fakePagingHandler{items: 0, total: func(n int) int { return n }},
+			filter:           DescribeInstancesRequest{Limit: 10},
+			expectedItems:    0,
+			expectedRequests: 1,
+		},
+	}
+
+	for _, test := range tests {
+		testServer := httptest.NewServer(test.handler)
+
+		c, err := NewClient(testServer.URL, &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"})
+		if err != nil {
+			t.Errorf("Unexpected non-nil error %v", err)
+		}
+
+		items, err := c.ListAllInstances(context.Background(), test.filter)
+		if err != nil {
+			t.Errorf("%s: unexpected non-nil error %v", test.name, err)
+		}
+		if len(items) != test.expectedItems {
+			t.Errorf("%s: expected %d items, got %d", test.name, test.expectedItems, len(items))
+		}
+		for i, item := range items {
+			if expected := fmt.Sprintf("i-%d", test.filter.Offset+i); item.InstanceID != expected {
+				t.Errorf("%s: expected item %s, got %s", test.name, expected, item.InstanceID)
+				break
+			}
+		}
+		if test.handler.requests != test.expectedRequests {
+			t.Errorf("%s: expected %d requests, got %d", test.name, test.expectedRequests, test.handler.requests)
+		}
+		testServer.Close()
+	}
+}
+
+// TestPagerError tests that pager stops on error.
+func TestPagerError(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"code": 1200, "ret_code": 1200, "message": "auth failure"}`))
+	}))
+	defer testServer.Close()
+
+	c, err := NewClient(testServer.URL, &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"})
+	if err != nil {
+		t.Errorf("Unexpected non-nil error %v", err)
+	}
+
+	pager := c.NewEipPager(DescribeEipsRequest{})
+	if pager.Next(context.Background()) {
+		t.Errorf("Expected no page on error")
+	}
+	if !IsAuthFailure(pager.Err()) {
+		t.Errorf("Expected auth failure error, got %v", pager.Err())
+	}
+	if pager.Next(context.Background()) {
+		t.Errorf("Expected pager to stay stopped")
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2015 anchnet-go authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package anchnet
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// DefaultProfileName is the profile used when neither the caller nor the config
+// file names one. A flat config file is read as a single profile of this name.
+const DefaultProfileName = "default"
+
+// Profile is a named set of API keys, along with endpoint and zone to use them.
+// Empty Endpoint and Zone mean DefaultEndpoint and DefaultZone.
+type Profile struct {
+	AuthConfiguration `json:",inline"`
+	Endpoint          string `json:"endpoint,omitempty"`
+	Zone              string `json:"zone,omitempty"`
+}
+
+// ProfileConfig is the content of anchnet config file. Besides the flat format with
+// a single set of keys (see README), the file can hold named profiles, e.g.
+//   {
+//     "default_profile": "main",
+//     "profiles": {
+//       "main": {"publickey": "...", "privatekey": "...", "zone": "ac1"},
+//       "sub":  {"publickey": "...", "privatekey": "...", "projectid": "pro-XXX", "zone": "ac2"}
+//     }
+//   }
+type ProfileConfig struct {
+	DefaultProfile string              `json:"default_profile,omitempty"`
+	Profiles       map[string]*Profile `json:"profiles,omitempty"`
+}
+
+// LoadProfileConfig loads a config file in either flat or profiles format.
+func LoadProfileConfig(path string) (*ProfileConfig, error) {
+	r, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return LoadProfileConfigReader(r)
+}
+
+// LoadProfileConfigReader loads a config file in either flat or profiles format
+// from given reader.
+func LoadProfileConfigReader(config io.Reader) (*ProfileConfig, error) {
+	var content struct {
+		Profile
+		ProfileConfig
+	}
+	if err := json.NewDecoder(config).Decode(&content); err != nil {
+		return nil, err
+	}
+	file := content.ProfileConfig
+	if file.Profiles == nil {
+		file.Profiles = make(map[string]*Profile)
+	}
+	// Flat format, i.e. keys at top level.
+	if content.PublicKey != "" || len(file.Profiles) == 0 {
+		if _, ok := file.Profiles[DefaultProfileName]; !ok {
+			file.Profiles[DefaultProfileName] = &content.Profile
+		}
+	}
+	return &file, nil
+}
+
+// Profile returns a copy of the named profile, with endpoint and zone defaulted.
+// An empty name means the default profile of the file.
+func (c *ProfileConfig) Profile(name string) (*Profile, error) {
+	if name == "" {
+		name = c.DefaultProfile
+	}
+	if name == "" {
+		name = DefaultProfileName
+	}
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("profile %q not found in config file, known profiles: %v", name, c.ProfileNames())
+	}
+	result := *profile
+	if result.Endpoint == "" {
+		result.Endpoint = DefaultEndpoint
+	}
+	if result.Zone == "" {
+		result.Zone = DefaultZone
+	}
+	return &result, nil
+}
+
+// ProfileNames returns sorted names of all profiles in the file.
+func (c *ProfileConfig) ProfileNames() []string {
+	var names []string
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadProfile loads the named profile from the default config file. An empty
+// name means the default profile of the file.
+func LoadProfile(name string) (*Profile, error) {
+	file, err := LoadProfileConfig(DefaultConfigPath())
+	if err != nil {
+		return nil, err
+	}
+	return file.Profile(name)
+}
+
+// NewClientFromProfile creates a new client with keys, endpoint and zone of the
+// given profile.
+func NewClientFromProfile(profile *Profile) (*Client, error) {
+	client, err := NewClient(profile.Endpoint, &profile.AuthConfiguration)
+	if err != nil {
+		return nil, err
+	}
+	if profile.Zone != "" {
+		client.SetZone(profile.Zone)
+	}
+	return client, nil
+}
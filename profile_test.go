@@ -0,0 +1,124 @@
+// Copyright 2015 anchnet-go authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package anchnet
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestProfileConfig tests reading profiles from flat and profiles config files.
+func TestProfileConfig(t *testing.T) {
+	tests := []struct {
+		config   string
+		name     string
+		expected *Profile
+	}{
+		{
+			config: `{"publickey": "key1", "privatekey": "secret1", "projectid": "pro-1"}`,
+			expected: &Profile{
+				AuthConfiguration: AuthConfiguration{PublicKey: "key1", PrivateKey: "secret1", ProjectId: "pro-1"},
+				Endpoint:          DefaultEndpoint,
+				Zone:              DefaultZone,
+			},
+		},
+		{
+			config: `{"publickey": "key1", "privatekey": "secret1", "zone": "ac2", "endpoint": "http://localhost"}`,
+			name:   DefaultProfileName,
+			expected: &Profile{
+				AuthConfiguration: AuthConfiguration{PublicKey: "key1", PrivateKey: "secret1"},
+				Endpoint:          "http://localhost",
+				Zone:              "ac2",
+			},
+		},
+		{
+			config: `{"default_profile": "main", "profiles": {
+			  "main": {"publickey": "key1", "privatekey": "secret1"},
+			  "asia": {"publickey": "key2", "privatekey": "secret2", "zone": "ac2"}}}`,
+			expected: &Profile{
+				AuthConfiguration: AuthConfiguration{PublicKey: "key1", PrivateKey: "secret1"},
+				Endpoint:          DefaultEndpoint,
+				Zone:              DefaultZone,
+			},
+		},
+		{
+			config: `{"default_profile": "main", "profiles": {
+			  "main": {"publickey": "key1", "privatekey": "secret1"},
+			  "asia": {"publickey": "key2", "privatekey": "secret2", "zone": "ac2"}}}`,
+			name: "asia",
+			expected: &Profile{
+				AuthConfiguration: AuthConfiguration{PublicKey: "key2", PrivateKey: "secret2"},
+				Endpoint:          DefaultEndpoint,
+				Zone:              "ac2",
+			},
+		},
+		{
+			// Flat keys sit next to profiles.
+			config: `{"publickey": "key1", "privatekey": "secret1", "profiles": {
+			  "asia": {"publickey": "key2", "privatekey": "secret2", "zone": "ac2"}}}`,
+			expected: &Profile{
+				AuthConfiguration: AuthConfiguration{PublicKey: "key1", PrivateKey: "secret1"},
+				Endpoint:          DefaultEndpoint,
+				Zone:              DefaultZone,
+			},
+		},
+	}
+
+	for i, test := range tests {
+		config, err := LoadProfileConfigReader(strings.NewReader(test.config))
+		if err != nil {
+			t.Errorf("Test %d: unexpected non-nil error %v", i, err)
+			continue
+		}
+		profile, err := config.Profile(test.name)
+		if err != nil {
+			t.Errorf("Test %d: unexpected non-nil error %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(test.expected, profile) {
+			t.Errorf("Test %d: expected \n%v, got \n%v", i, test.expected, profile)
+		}
+	}
+
+	config, err := LoadProfileConfigReader(strings.NewReader(`{"profiles": {"asia": {"publickey": "key2"}}}`))
+	if err != nil {
+		t.Errorf("Unexpected non-nil error %v", err)
+	}
+	if _, err := config.Profile(""); err == nil {
+		t.Errorf("Expected error for missing default profile")
+	}
+}
+
+// TestLoadProfile tests loading profiles from default config path.
+func TestLoadProfile(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	os.MkdirAll(filepath.Join(home, ConfigDir), 0700)
+	content := `{"profiles": {"asia": {"publickey": "key2", "privatekey": "secret2", "zone": "ac2"}}}`
+	if err := ioutil.WriteFile(DefaultConfigPath(), []byte(content), 0600); err != nil {
+		t.Fatalf("Unexpected error writing config: %v", err)
+	}
+
+	profile, err := LoadProfile("asia")
+	if err != nil {
+		t.Fatalf("Unexpected non-nil error %v", err)
+	}
+	client, err := NewClientFromProfile(profile)
+	if err != nil {
+		t.Errorf("Unexpected non-nil error %v", err)
+	}
+	if client.zone != "ac2" || client.endpoint != DefaultEndpoint {
+		t.Errorf("Expected client in zone ac2 with default endpoint, got %v, %v", client.zone, client.endpoint)
+	}
+
+	// LoadConfig can't pick a profile if there is no default one.
+	if _, err := LoadConfig(DefaultConfigPath()); err == nil {
+		t.Errorf("Expected error loading config without default profile")
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright 2015 anchnet-go authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package anchnet
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// RateLimit limits how fast requests are sent, see RateLimiter.
+type RateLimit struct {
+	// QPS is the sustained number of requests per second. Zero means unlimited.
+	QPS float64
+	// Burst is the number of requests which can be sent at once after being idle.
+	// Values less than 1 are treated as 1.
+	Burst int
+	// MaxInFlight is the max number of requests waiting for a response. Zero
+	// means unlimited.
+	MaxInFlight int
+}
+
+// Adaptation of the rate after throttling, see RateLimiter.
+const (
+	throttleBackoff = 2  // Rate is divided by this when anchnet throttles requests,
+	minRateFactor   = 16 // but stays above QPS/minRateFactor.
+	recoverySteps   = 20 // Every successful request gains back QPS/recoverySteps.
+)
+
+// RateLimiter throttles requests of a Client, see Client.RateLimiter. Each request
+// attempt, including retries, waits for a token from a token bucket and for a free
+// in-flight slot. Actions can have their own limits; other actions share the
+// default limit.
+//
+// When anchnet signals throttling (see IsThrottled), the rate of the action's
+// bucket is halved, down to 1/16 of its QPS, then recovers gradually as requests
+// succeed. Buckets with unlimited QPS don't adapt.
+type RateLimiter struct {
+	mu      sync.Mutex
+	def     *bucket
+	actions map[string]*bucket
+}
+
+// NewRateLimiter returns a rate limiter applying limit to all actions.
+func NewRateLimiter(limit RateLimit) *RateLimiter {
+	return &RateLimiter{def: newBucket(limit), actions: make(map[string]*bucket)}
+}
+
+// SetActionLimit gives action its own limit, e.g. RunInstances. Requests of the
+// action no longer count against the default limit.
+func (l *RateLimiter) SetActionLimit(action string, limit RateLimit) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.actions[action] = newBucket(limit)
+}
+
+// Rate returns the current QPS allowed for action, after adaptation. It is zero if
+// unlimited.
+func (l *RateLimiter) Rate(action string) float64 {
+	b := l.bucket(action)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.rate
+}
+
+func (l *RateLimiter) bucket(action string) *bucket {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if b, ok := l.actions[action]; ok {
+		return b
+	}
+	return l.def
+}
+
+// acquire waits until a request of action can be sent, or ctx is done. release
+// must be called with the result of the request once it's finished.
+func (l *RateLimiter) acquire(ctx context.Context, action string) (release func(err error), err error) {
+	b := l.bucket(action)
+	if err := b.wait(ctx); err != nil {
+		return nil, err
+	}
+	if b.inFlight != nil {
+		select {
+		case b.inFlight <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return func(err error) {
+		if b.inFlight != nil {
+			<-b.inFlight
+		}
+		b.adapt(err)
+	}, nil
+}
+
+// bucket is a token bucket, refilled at rate tokens per second.
+type bucket struct {
+	limit    RateLimit
+	inFlight chan struct{} // Semaphore of in-flight requests, nil if unlimited
+
+	mu     sync.Mutex
+	rate   float64
+	tokens float64
+	last   time.Time
+}
+
+func newBucket(limit RateLimit) *bucket {
+	if limit.Burst < 1 {
+		limit.Burst = 1
+	}
+	b := &bucket{limit: limit, rate: limit.QPS, tokens: float64(limit.Burst), last: time.Now()}
+	if limit.MaxInFlight > 0 {
+		b.inFlight = make(chan struct{}, limit.MaxInFlight)
+	}
+	return b
+}
+
+// wait takes a token, waiting until one is available or ctx is done.
+func (b *bucket) wait(ctx context.Context) error {
+	if b.limit.QPS <= 0 {
+		return nil
+	}
+	// Reserve a token now, possibly making tokens negative; callers are served in
+	// the order they reserve.
+	b.mu.Lock()
+	now := time.Now()
+	b.tokens = math.Min(float64(b.limit.Burst), b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	b.tokens--
+	delay := time.Duration(-b.tokens / b.rate * float64(time.Second))
+	b.mu.Unlock()
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		// Give back the reservation.
+		b.mu.Lock()
+		b.tokens++
+		b.mu.Unlock()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// adapt slows the bucket down if err means anchnet throttles requests, or speeds
+// it back up if the request succeeded.
+func (b *bucket) adapt(err error) {
+	if b.limit.QPS <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch {
+	case IsThrottled(err):
+		b.rate = math.Max(b.rate/throttleBackoff, b.limit.QPS/minRateFactor)
+	case err == nil:
+		b.rate = math.Min(b.rate+b.limit.QPS/recoverySteps, b.limit.QPS)
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright 2015 anchnet-go authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package anchnet
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestRateLimit tests that requests are spaced out by the token bucket.
+func TestRateLimit(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ret_code": 0, "code": 0}`))
+	}))
+	defer testServer.Close()
+
+	c, err := NewClient(testServer.URL, &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"})
+	if err != nil {
+		t.Errorf("Unexpected non-nil error %v", err)
+	}
+	c.RateLimiter = NewRateLimiter(RateLimit{QPS: 100, Burst: 2})
+	c.RateLimiter.SetActionLimit("DescribeInstances", RateLimit{})
+
+	// Burst of 2, then one request every 10ms.
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		if err := c.SendRequest(DescribeJobsRequest{}, &DescribeJobsResponse{}); err != nil {
+			t.Errorf("Unexpected non-nil error %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 35*time.Millisecond {
+		t.Errorf("Expected requests to take at least 40ms, took %v", elapsed)
+	}
+
+	// Actions with their own limit are not throttled by the default one.
+	start = time.Now()
+	for i := 0; i < 6; i++ {
+		if err := c.SendRequest(DescribeInstancesRequest{}, &DescribeInstancesResponse{}); err != nil {
+			t.Errorf("Unexpected non-nil error %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 30*time.Millisecond {
+		t.Errorf("Expected unlimited requests, took %v", elapsed)
+	}
+
+	// Waiting for a token is aborted with ctx.
+	c.RateLimiter = NewRateLimiter(RateLimit{QPS: 0.1})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	for i := 0; i < 2; i++ {
+		err = c.SendRequestWithContext(ctx, DescribeJobsRequest{}, &DescribeJobsResponse{})
+	}
+	if err != context.DeadlineExceeded {
+		t.Errorf("Expected deadline exceeded, got %v", err)
+	}
+}
+
+// TestMaxInFlight tests that concurrent requests are limited.
+func TestMaxInFlight(t *testing.T) {
+	var inFlight, maxInFlight int32
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+		w.Write([]byte(`{"ret_code": 0, "code": 0}`))
+	}))
+	defer testServer.Close()
+
+	c, err := NewClient(testServer.URL, &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"})
+	if err != nil {
+		t.Errorf("Unexpected non-nil error %v", err)
+	}
+	c.RateLimiter = NewRateLimiter(RateLimit{MaxInFlight: 2})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := c.SendRequest(DescribeJobsRequest{}, &DescribeJobsResponse{}); err != nil {
+				t.Errorf("Unexpected non-nil error %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if maxInFlight != 2 {
+		t.Errorf("Expected at most 2 requests in flight, got %d", maxInFlight)
+	}
+}
+
+// TestRateLimitAdapt tests that the rate drops when anchnet throttles requests,
+// and recovers after successful requests.
+func TestRateLimitAdapt(t *testing.T) {
+	handler := &flakyHandler{
+		failures: 5,
+		status:   http.StatusOK,
+		body:     `{"code": 5100, "ret_code": 5100, "message": "busy"}`,
+		response: `{"ret_code": 0, "code": 0}`,
+	}
+	testServer := httptest.NewServer(handler)
+	defer testServer.Close()
+
+	c, err := NewClient(testServer.URL, &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"})
+	if err != nil {
+		t.Errorf("Unexpected non-nil error %v", err)
+	}
+	c.RetryPolicy = NoRetry
+	c.RateLimiter = NewRateLimiter(RateLimit{QPS: 10000, Burst: 100})
+
+	expectedRates := []float64{5000, 2500, 1250, 625, 625, 1125, 1625}
+	for i, expected := range expectedRates {
+		c.SendRequest(DescribeJobsRequest{}, &DescribeJobsResponse{})
+		if rate := c.RateLimiter.Rate("DescribeJobs"); rate != expected {
+			t.Errorf("Request %d: expected rate %v, got %v", i, expected, rate)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2015 anchnet-go authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package redact removes secrets, e.g. API token or login password, from anchnet
+// request and response bodies before they are logged or written to disk. It
+// doesn't import other anchnet packages, so that all of them can use it.
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// Redacted replaces values of secret fields.
+const Redacted = "REDACTED"
+
+// Fields are json fields whose values are secrets, in requests or responses.
+var Fields = map[string]bool{
+	"token":        true,
+	"signature":    true,
+	"password":     true, // RunInstancesVM.Password
+	"login_passwd": true, // ResetLoginPasswdRequest.LoginPasswd
+	"loginPasswd":  true, // CreateUserProjectRequest.LoginPasswd
+	"private_key":  true, // CreateKeyPairResponse.PrivateKey
+	"userdata":     true, // RunInstancesVM.UserData, e.g. cloud-init scripts with credentials
+}
+
+// JSON returns json body with values of Fields replaced by Redacted, in objects at
+// any depth. Other json is returned unchanged, except that object keys are sorted.
+// Body which isn't valid json is returned as is.
+func JSON(body []byte) []byte {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil || decoder.More() {
+		return body
+	}
+	redacted, err := json.Marshal(value(v))
+	if err != nil {
+		return body
+	}
+	return redacted
+}
+
+// value redacts a decoded json value in place, and returns it.
+func value(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if Fields[key] {
+				v[key] = Redacted
+			} else {
+				v[key] = value(field)
+			}
+		}
+	case []interface{}:
+		for i, elem := range v {
+			v[i] = value(elem)
+		}
+	}
+	return v
+}
@@ -0,0 +1,189 @@
+// Copyright 2015 anchnet-go authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package replay records anchnet request/response pairs to disk, and replays them
+// later, e.g. to turn real anchnet traffic into regression fixtures. Install the
+// transport in anchnet.Client:
+//   client.HTTPClient = &http.Client{Transport: &replay.Transport{Dir: "testdata", Mode: replay.Record}}
+//
+// Each pair is written to Dir as <action>-<n>.json, where n counts requests of the
+// action from 1. Secrets in request and response, e.g. token, login password or
+// private key (see package redact), and the signature header are redacted, so the
+// files can be checked in.
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+
+	"github.com/caicloud/anchnet-go/redact"
+)
+
+// Mode tells Transport whether to record or replay.
+type Mode int
+
+const (
+	// Record sends requests to anchnet and writes request/response pairs to disk.
+	Record Mode = iota
+	// Replay reads responses from disk; no request is sent.
+	Replay
+)
+
+// Interaction is a recorded request/response pair, i.e. content of a file.
+type Interaction struct {
+	Request    json.RawMessage `json:"request"`
+	Signature  string          `json:"signature,omitempty"`
+	StatusCode int             `json:"status_code"`
+	Response   json.RawMessage `json:"response"`
+}
+
+// Transport is an http.RoundTripper recording or replaying anchnet requests.
+type Transport struct {
+	// Dir is the directory of recorded files.
+	Dir  string
+	Mode Mode
+	// MatchRequest makes replay fail if a request differs from the recorded one,
+	// ignoring token.
+	MatchRequest bool
+	// Transport sends requests when recording. http.DefaultTransport is used if nil.
+	Transport http.RoundTripper
+
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+	}
+	request, action, err := decodeRequest(body)
+	if err != nil {
+		return nil, err
+	}
+	path := t.next(action)
+
+	if t.Mode == Replay {
+		return t.replay(req, path, request)
+	}
+
+	transport := t.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	sent := req.Clone(req.Context())
+	sent.Body = ioutil.NopCloser(bytes.NewReader(body))
+	resp, err := transport.RoundTrip(sent)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+	interaction := Interaction{Request: request, StatusCode: resp.StatusCode, Response: redact.JSON(respBody)}
+	if req.Header.Get("signature") != "" {
+		interaction.Signature = redact.Redacted
+	}
+	// Non-JSON responses, e.g. from a proxy, are kept as a JSON string.
+	if !json.Valid(respBody) {
+		interaction.Response, _ = json.Marshal(string(respBody))
+	}
+	buf, err := json.MarshalIndent(interaction, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(t.Dir, 0755); err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(path, append(buf, '\n'), 0644); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// next returns path of the file of the next request of action.
+func (t *Transport) next(action string) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.counts == nil {
+		t.counts = make(map[string]int)
+	}
+	t.counts[action]++
+	return filepath.Join(t.Dir, fmt.Sprintf("%s-%d.json", action, t.counts[action]))
+}
+
+func (t *Transport) replay(req *http.Request, path string, request []byte) (*http.Response, error) {
+	interaction, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	if t.MatchRequest {
+		var expected, actual interface{}
+		json.Unmarshal(interaction.Request, &expected)
+		json.Unmarshal(request, &actual)
+		if !reflect.DeepEqual(expected, actual) {
+			return nil, fmt.Errorf("request doesn't match %v, expected \n%s, got \n%s", path, interaction.Request, request)
+		}
+	}
+	body := []byte(interaction.Response)
+	var text string
+	if json.Unmarshal(body, &text) == nil {
+		body = []byte(text)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", interaction.StatusCode, http.StatusText(interaction.StatusCode)),
+		StatusCode:    interaction.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"application/json"}},
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// Load reads a recorded interaction from path.
+func Load(path string) (*Interaction, error) {
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var interaction Interaction
+	if err := json.Unmarshal(buf, &interaction); err != nil {
+		return nil, fmt.Errorf("error decoding %v: %v", path, err)
+	}
+	return &interaction, nil
+}
+
+// decodeRequest returns request body with secrets redacted, and action of the
+// request.
+func decodeRequest(body []byte) ([]byte, string, error) {
+	var request struct {
+		Action string `json:"action"`
+	}
+	if err := json.Unmarshal(body, &request); err != nil {
+		return nil, "", fmt.Errorf("error decoding anchnet request: %v", err)
+	}
+	if request.Action == "" {
+		return nil, "", fmt.Errorf("anchnet request has no action: %s", body)
+	}
+	return redact.JSON(body), request.Action, nil
+}
@@ -0,0 +1,148 @@
+// Copyright 2015 anchnet-go authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	anchnet "github.com/caicloud/anchnet-go"
+	"github.com/caicloud/anchnet-go/anchnettest"
+	"github.com/caicloud/anchnet-go/redact"
+)
+
+// TestRecordReplay tests that recorded traffic is redacted, and replays to the same
+// responses without server.
+func TestRecordReplay(t *testing.T) {
+	server := anchnettest.NewServer()
+	server.JobDuration = 0
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	client := server.Client()
+	client.HTTPClient = &http.Client{Transport: &Transport{Dir: dir, Mode: Record}}
+	create, err := client.CreateVxnets(ctx, &anchnet.CreateVxnetsRequest{VxnetName: "test", Count: 2})
+	if err != nil {
+		t.Fatalf("Unexpected non-nil error %v", err)
+	}
+	var recorded []*anchnet.DescribeVxnetsResponse
+	for i := 0; i < 2; i++ {
+		response, err := client.DescribeVxnets(ctx, &anchnet.DescribeVxnetsRequest{VxnetIDs: create.VxnetIDs[i : i+1]})
+		if err != nil {
+			t.Fatalf("Unexpected non-nil error %v", err)
+		}
+		recorded = append(recorded, response)
+	}
+	server.Close()
+
+	paths, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	expectedPaths := []string{
+		filepath.Join(dir, "CreateVxnets-1.json"),
+		filepath.Join(dir, "DescribeVxnets-1.json"),
+		filepath.Join(dir, "DescribeVxnets-2.json"),
+	}
+	if !reflect.DeepEqual(expectedPaths, paths) {
+		t.Errorf("Expected files %v, got %v", expectedPaths, paths)
+	}
+	for _, path := range paths {
+		content, _ := ioutil.ReadFile(path)
+		if strings.Contains(string(content), anchnettest.PublicKey) {
+			t.Errorf("Expected token redacted in %v, got %s", path, content)
+		}
+		interaction, err := Load(path)
+		if err != nil || interaction.Signature != redact.Redacted || interaction.StatusCode != http.StatusOK {
+			t.Errorf("Unexpected interaction %+v, %v", interaction, err)
+		}
+	}
+
+	client.HTTPClient = &http.Client{Transport: &Transport{Dir: dir, Mode: Replay, MatchRequest: true}}
+	if _, err := client.CreateVxnets(ctx, &anchnet.CreateVxnetsRequest{VxnetName: "test", Count: 2}); err != nil {
+		t.Fatalf("Unexpected non-nil error %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		response, err := client.DescribeVxnets(ctx, &anchnet.DescribeVxnetsRequest{VxnetIDs: create.VxnetIDs[i : i+1]})
+		if err != nil {
+			t.Fatalf("Unexpected non-nil error %v", err)
+		}
+		if !reflect.DeepEqual(recorded[i], response) {
+			t.Errorf("Expected replayed response \n%+v, got \n%+v", recorded[i], response)
+		}
+	}
+
+	// Requests which don't match recorded ones are rejected.
+	client.HTTPClient = &http.Client{Transport: &Transport{Dir: dir, Mode: Replay, MatchRequest: true}}
+	if _, err := client.CreateVxnets(ctx, &anchnet.CreateVxnetsRequest{VxnetName: "other"}); err == nil {
+		t.Errorf("Expected error replaying different request")
+	}
+}
+
+// TestRecordRedactsSecrets tests that secrets in requests and responses are not
+// written to disk, while the client still gets them.
+func TestRecordRedactsSecrets(t *testing.T) {
+	server := anchnettest.NewServer()
+	defer server.Close()
+	server.JobDuration = 0
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	client := server.Client()
+	client.HTTPClient = &http.Client{Transport: &Transport{Dir: dir, Mode: Record}}
+	_, err := client.RunInstances(ctx, &anchnet.RunInstancesRequest{
+		Product: anchnet.RunInstancesProduct{
+			Cloud: anchnet.RunInstancesCloud{
+				VM: anchnet.RunInstancesVM{
+					Name:      "test",
+					LoginMode: anchnet.LoginModePwd,
+					Password:  "caicloud2015ABC",
+					Mem:       1024,
+					Cpu:       1,
+					ImageID:   "opensuse12x64c",
+					UserData:  []byte("#!/bin/sh\necho hello\n"),
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected non-nil error %v", err)
+	}
+	create, err := client.CreateKeyPair(ctx, &anchnet.CreateKeyPairRequest{
+		KeyPairName:   "test",
+		Mode:          anchnet.KeyPairModeSystem,
+		EncryptMethod: anchnet.KeyPairEncryptMethodRSA,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected non-nil error %v", err)
+	}
+	if create.PrivateKey == "" {
+		t.Errorf("Expected private key in response, got %+v", create)
+	}
+
+	secrets := []string{
+		anchnettest.PublicKey,
+		"caicloud2015ABC",
+		"IyEvYmluL3NoCmVjaG8gaGVsbG8K", // Base64 of user data
+		create.PrivateKey,
+	}
+	for _, name := range []string{"RunInstances-1.json", "CreateKeyPair-1.json"} {
+		content, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Errorf("Unexpected non-nil error %v", err)
+			continue
+		}
+		for _, secret := range secrets {
+			// Secrets are escaped in json files, e.g. newlines of private key.
+			escaped, _ := json.Marshal(secret)
+			if strings.Contains(string(content), strings.Trim(string(escaped), `"`)) {
+				t.Errorf("Expected %q redacted in %v, got %s", secret, name, content)
+			}
+		}
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2015 anchnet-go authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package anchnet
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// RetryPolicy controls how Client retries requests which fail transiently, e.g.
+// network errors or 5xx responses from anchnet. Only read-only actions (Describe*
+// and GetChargeSummary) are retried unless RetryMutating is set.
+type RetryPolicy struct {
+	// MaxAttempts is the max number of attempts, including the first one. Values
+	// less than 2 disable retry.
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry. Delay doubles on every retry,
+	// up to MaxDelay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// Jitter is the fraction of each delay which is randomized, between 0 and 1.
+	// E.g. with Jitter 0.2, a delay of 1s becomes a random value in [0.8s, 1s].
+	Jitter float64
+	// RetryMutating allows retrying actions which create or change resources, e.g.
+	// RunInstances. Note if anchnet processed a request but the response was lost,
+	// the retry may apply it twice.
+	RetryMutating bool
+	// Retryable decides whether a failed attempt can be retried. IsRetryable is
+	// used if nil.
+	Retryable func(err error) bool
+}
+
+// DefaultRetryPolicy is the retry policy of new clients.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    10 * time.Second,
+	Jitter:      0.2,
+}
+
+// NoRetry disables retry.
+var NoRetry = RetryPolicy{MaxAttempts: 1}
+
+// IsRetryable returns true if err is likely transient: network errors, http
+// 429 and 5xx responses, and anchnet internal error or server busy codes.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if apiErr, ok := IsAPIError(err); ok {
+		switch apiErr.Code {
+		case ErrorCodeInternalError, ErrorCodeServerBusy:
+			return true
+		}
+		return apiErr.HTTPStatus == http.StatusTooManyRequests || apiErr.HTTPStatus >= http.StatusInternalServerError
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// IsReadOnlyAction returns true if action doesn't change any resource.
+func IsReadOnlyAction(action string) bool {
+	return strings.HasPrefix(action, "Describe") || action == "GetChargeSummary"
+}
+
+// shouldRetry returns true if a request of action can be sent again after
+// 'attempt' attempts failed with err.
+func (p RetryPolicy) shouldRetry(action string, attempt int, err error) bool {
+	if attempt >= p.MaxAttempts {
+		return false
+	}
+	if !p.RetryMutating && !IsReadOnlyAction(action) {
+		return false
+	}
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+	return IsRetryable(err)
+}
+
+// delay returns how long to wait after 'attempt' attempts failed.
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	d := p.BaseDelay
+	for i := 1; i < attempt && (p.MaxDelay <= 0 || d < p.MaxDelay); i++ {
+		d *= 2
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	if p.Jitter > 0 {
+		d -= time.Duration(p.Jitter * rand.Float64() * float64(d))
+	}
+	return d
+}
+
+// wait sleeps before the next attempt, or returns early with error if ctx is done.
+func (p RetryPolicy) wait(ctx context.Context, attempt int) error {
+	timer := time.NewTimer(p.delay(attempt))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright 2015 anchnet-go authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package anchnet
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// flakyHandler fails the first 'failures' requests with 'status' and 'body', then
+// responds with 'response'.
+type flakyHandler struct {
+	failures int32
+	status   int
+	body     string
+	response string
+
+	requests int32
+}
+
+func (f *flakyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if atomic.AddInt32(&f.requests, 1) <= f.failures {
+		w.WriteHeader(f.status)
+		w.Write([]byte(f.body))
+		return
+	}
+	w.Write([]byte(f.response))
+}
+
+// TestRetry tests that transient failures are retried according to retry policy.
+func TestRetry(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond, Jitter: 0.5}
+	mutating := policy
+	mutating.RetryMutating = true
+
+	tests := []struct {
+		policy           RetryPolicy
+		request          Request
+		response         commonResponse
+		handler          *flakyHandler
+		expectError      bool
+		expectedRequests int32
+	}{
+		{
+			// Describe calls are retried by default.
+			policy:           policy,
+			request:          DescribeJobsRequest{JobIDs: []string{"job-ZUBILH5I"}},
+			response:         &DescribeJobsResponse{},
+			handler:          &flakyHandler{failures: 2, status: http.StatusServiceUnavailable, body: "busy"},
+			expectError:      false,
+			expectedRequests: 3,
+		},
+		{
+			// Give up after MaxAttempts.
+			policy:           policy,
+			request:          DescribeJobsRequest{JobIDs: []string{"job-ZUBILH5I"}},
+			response:         &DescribeJobsResponse{},
+			handler:          &flakyHandler{failures: 5, status: http.StatusBadGateway, body: "bad gateway"},
+			expectError:      true,
+			expectedRequests: 3,
+		},
+		{
+			// Anchnet server busy code is retried.
+			policy:           policy,
+			request:          DescribeJobsRequest{JobIDs: []string{"job-ZUBILH5I"}},
+			response:         &DescribeJobsResponse{},
+			handler:          &flakyHandler{failures: 1, status: http.StatusOK, body: `{"code": 5100, "ret_code": 5100, "message": "busy"}`},
+			expectError:      false,
+			expectedRequests: 2,
+		},
+		{
+			// Not found is not transient.
+			policy:           policy,
+			request:          DescribeJobsRequest{JobIDs: []string{"job-ZUBILH5I"}},
+			response:         &DescribeJobsResponse{},
+			handler:          &flakyHandler{failures: 1, status: http.StatusOK, body: `{"code": 2100, "ret_code": 2100, "message": "not found"}`},
+			expectError:      true,
+			expectedRequests: 1,
+		},
+		{
+			// Mutating calls are not retried by default.
+			policy:           policy,
+			request:          StopInstancesRequest{InstanceIDs: []string{"i-G74Q69NJ"}},
+			response:         &StopInstancesResponse{},
+			handler:          &flakyHandler{failures: 1, status: http.StatusServiceUnavailable, body: "busy"},
+			expectError:      true,
+			expectedRequests: 1,
+		},
+		{
+			// Mutating calls are retried if caller opts in.
+			policy:           mutating,
+			request:          StopInstancesRequest{InstanceIDs: []string{"i-G74Q69NJ"}},
+			response:         &StopInstancesResponse{},
+			handler:          &flakyHandler{failures: 1, status: http.StatusServiceUnavailable, body: "busy"},
+			expectError:      false,
+			expectedRequests: 2,
+		},
+		{
+			// Retry disabled.
+			policy:           NoRetry,
+			request:          DescribeJobsRequest{JobIDs: []string{"job-ZUBILH5I"}},
+			response:         &DescribeJobsResponse{},
+			handler:          &flakyHandler{failures: 1, status: http.StatusServiceUnavailable, body: "busy"},
+			expectError:      true,
+			expectedRequests: 1,
+		},
+	}
+
+	for i, test := range tests {
+		test.handler.response = `{"ret_code": 0, "code": 0, "job_id": "job-ZUBILH5I"}`
+		testServer := httptest.NewServer(test.handler)
+
+		c, err := NewClient(testServer.URL, &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"})
+		if err != nil {
+			t.Errorf("Unexpected non-nil error %v", err)
+		}
+		c.RetryPolicy = test.policy
+
+		err = c.SendRequest(test.request, test.response)
+		if test.expectError && err == nil {
+			t.Errorf("Test %d: unexpected nil error", i)
+		}
+		if !test.expectError && err != nil {
+			t.Errorf("Test %d: unexpected non-nil error %v", i, err)
+		}
+		if !test.expectError && test.response.responseCommon().Code != 0 {
+			t.Errorf("Test %d: expected response of the last attempt, got %+v", i, test.response)
+		}
+		if requests := atomic.LoadInt32(&test.handler.requests); requests != test.expectedRequests {
+			t.Errorf("Test %d: expected %d requests, got %d", i, test.expectedRequests, requests)
+		}
+		testServer.Close()
+	}
+}
+
+// TestRetryDelay tests exponential backoff with jitter.
+func TestRetryDelay(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second, Jitter: 0.2}
+	tests := []struct {
+		attempt int
+		max     time.Duration
+	}{
+		{attempt: 1, max: 100 * time.Millisecond},
+		{attempt: 2, max: 200 * time.Millisecond},
+		{attempt: 3, max: 400 * time.Millisecond},
+		{attempt: 5, max: time.Second},
+		{attempt: 50, max: time.Second},
+	}
+	for _, test := range tests {
+		for i := 0; i < 10; i++ {
+			d := policy.delay(test.attempt)
+			if min := test.max * 8 / 10; d < min || d > test.max {
+				t.Errorf("Expected delay of attempt %d in [%v, %v], got %v", test.attempt, min, test.max, d)
+			}
+		}
+	}
+}
@@ -4,6 +4,10 @@
 
 package anchnet
 
+import (
+	"context"
+)
+
 // Implements all anchnet security group (firewall) related APIs.
 
 //
@@ -18,6 +22,15 @@ type DescribeSecurityGroupsRequest struct {
 	Limit            int      `json:"limit,omitempty"`
 }
 
+func (DescribeSecurityGroupsRequest) ActionName() string { return "DescribeSecurityGroups" }
+
+// DescribeSecurityGroups sends DescribeSecurityGroupsRequest to anchnet.
+func (c *Client) DescribeSecurityGroups(ctx context.Context, request *DescribeSecurityGroupsRequest) (*DescribeSecurityGroupsResponse, error) {
+	var response DescribeSecurityGroupsResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type DescribeSecurityGroupsResponse struct {
 	ResponseCommon `json:",inline"`
 	TotalCount     int                          `json:"total_count,omitempty"`
@@ -28,11 +41,12 @@ type DescribeSecurityGroupsItem struct {
 	SecurityGroupID    string                      `json:"security_group_id,omitempty"`
 	SecurityGroupName  string                      `json:"security_group_name,omitempty"`
 	Description        string                      `json:"description,omitempty"`
-	CreateTime         string                      `json:"create_time,omitempty"`
+	CreateTime         Time                        `json:"create_time,omitempty"`
 	Disabled           int                         `json:"disabled,omitempty"`
 	IsDefault          int                         `json:"is_default,omitempty"`
 	IsApplied          int                         `json:"is_applied,omitempty"`
 	SecurityGroupRules []DescribeSecurityGroupRule `json:"rule,omitempty"`
+	Resources          []SecurityGroupResource     `json:"resource,omitempty"` // Instances and loadbalancers using the group
 }
 
 type DescribeSecurityGroupRule struct {
@@ -47,6 +61,7 @@ type DescribeSecurityGroupRule struct {
 	Value2                string                     `json:"val2,omitempty"`
 	Value3                string                     `json:"val3,omitempty"`
 	Resources             []SecurityGroupResource    `json:"resource,omitempty"`
+	SecurityGroupID       string                     `json:"security_group_id,omitempty"` // Only set by DescribeSecurityGroupRules
 }
 
 type SecurityGroupResource struct {
@@ -86,6 +101,15 @@ type CreateSecurityGroupRequest struct {
 	SecurityGroupRules []CreateSecurityGroupRule `json:"rule,omitempty"`
 }
 
+func (CreateSecurityGroupRequest) ActionName() string { return "CreateSecurityGroup" }
+
+// CreateSecurityGroup sends CreateSecurityGroupRequest to anchnet.
+func (c *Client) CreateSecurityGroup(ctx context.Context, request *CreateSecurityGroupRequest) (*CreateSecurityGroupResponse, error) {
+	var response CreateSecurityGroupResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type CreateSecurityGroupResponse struct {
 	ResponseCommon  `json:",inline"`
 	JobID           string `json:"job_id,omitempty"`
@@ -114,6 +138,20 @@ type DeleteSecurityGroupsRequest struct {
 	SecurityGroupIDs []string `json:"security_groups,omitempty"`
 }
 
+func (DeleteSecurityGroupsRequest) ActionName() string { return "DeleteSecurityGroups" }
+
+// Validate implements Validator.
+func (r DeleteSecurityGroupsRequest) Validate() error {
+	return requireIDs(r.ActionName(), "SecurityGroupIDs", r.SecurityGroupIDs)
+}
+
+// DeleteSecurityGroups sends DeleteSecurityGroupsRequest to anchnet.
+func (c *Client) DeleteSecurityGroups(ctx context.Context, request *DeleteSecurityGroupsRequest) (*DeleteSecurityGroupsResponse, error) {
+	var response DeleteSecurityGroupsResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type DeleteSecurityGroupsResponse struct {
 	ResponseCommon `json:",inline"`
 	JobID          string `json:"job_id,omitempty"`
@@ -126,6 +164,20 @@ type ApplySecurityGroupRequest struct {
 	InstanceIDs     []string `json:"instances,omitempty"`
 }
 
+func (ApplySecurityGroupRequest) ActionName() string { return "ApplySecurityGroup" }
+
+// Validate implements Validator.
+func (r ApplySecurityGroupRequest) Validate() error {
+	return requireID(r.ActionName(), "SecurityGroupID", r.SecurityGroupID)
+}
+
+// ApplySecurityGroup sends ApplySecurityGroupRequest to anchnet.
+func (c *Client) ApplySecurityGroup(ctx context.Context, request *ApplySecurityGroupRequest) (*ApplySecurityGroupResponse, error) {
+	var response ApplySecurityGroupResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type ApplySecurityGroupResponse struct {
 	ResponseCommon `json:",inline"`
 	JobID          string `json:"job_id,omitempty"`
@@ -141,6 +193,20 @@ type ModifySecurityGroupAttributesRequest struct {
 	Description       string `json:"description,omitempty"`
 }
 
+func (ModifySecurityGroupAttributesRequest) ActionName() string { return "ModifySecurityGroupAttributes" }
+
+// Validate implements Validator.
+func (r ModifySecurityGroupAttributesRequest) Validate() error {
+	return requireID(r.ActionName(), "SecurityGroupID", r.SecurityGroupID)
+}
+
+// ModifySecurityGroupAttributes sends ModifySecurityGroupAttributesRequest to anchnet.
+func (c *Client) ModifySecurityGroupAttributes(ctx context.Context, request *ModifySecurityGroupAttributesRequest) (*ModifySecurityGroupAttributesResponse, error) {
+	var response ModifySecurityGroupAttributesResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type ModifySecurityGroupAttributesResponse struct {
 	ResponseCommon  `json:",inline"`
 	JobID           string `json:"job_id,omitempty"`
@@ -158,6 +224,15 @@ type DescribeSecurityGroupRulesRequest struct {
 	Direction            SecurityGroupRuleDirection `json:"direction,omitempty"`
 }
 
+func (DescribeSecurityGroupRulesRequest) ActionName() string { return "DescribeSecurityGroupRules" }
+
+// DescribeSecurityGroupRules sends DescribeSecurityGroupRulesRequest to anchnet.
+func (c *Client) DescribeSecurityGroupRules(ctx context.Context, request *DescribeSecurityGroupRulesRequest) (*DescribeSecurityGroupRulesResponse, error) {
+	var response DescribeSecurityGroupRulesResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type DescribeSecurityGroupRulesResponse struct {
 	ResponseCommon `json:",inline"`
 	TotalCount     int                         `json:"total_count,omitempty"`
@@ -173,6 +248,24 @@ type AddSecurityGroupRulesRequest struct {
 	SecurityGroupRules []AddSecurityGroupRule `json:"rules,omitempty"`
 }
 
+func (AddSecurityGroupRulesRequest) ActionName() string { return "AddSecurityGroupRules" }
+
+// Validate implements Validator.
+func (r AddSecurityGroupRulesRequest) Validate() error {
+	err := requireID(r.ActionName(), "SecurityGroupID", r.SecurityGroupID)
+	if err == nil && len(r.SecurityGroupRules) == 0 {
+		err = invalid(r.ActionName(), "SecurityGroupRules", "is required")
+	}
+	return err
+}
+
+// AddSecurityGroupRules sends AddSecurityGroupRulesRequest to anchnet.
+func (c *Client) AddSecurityGroupRules(ctx context.Context, request *AddSecurityGroupRulesRequest) (*AddSecurityGroupRulesResponse, error) {
+	var response AddSecurityGroupRulesResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type AddSecurityGroupRulesResponse struct {
 	ResponseCommon       `json:",inline"`
 	JobID                string   `json:"job_id,omitempty"`
@@ -201,6 +294,20 @@ type DeleteSecurityGroupRulesRequest struct {
 	SecurityGroupRuleIDs []string `json:"security_group_rules,omitempty"`
 }
 
+func (DeleteSecurityGroupRulesRequest) ActionName() string { return "DeleteSecurityGroupRules" }
+
+// Validate implements Validator.
+func (r DeleteSecurityGroupRulesRequest) Validate() error {
+	return requireIDs(r.ActionName(), "SecurityGroupRuleIDs", r.SecurityGroupRuleIDs)
+}
+
+// DeleteSecurityGroupRules sends DeleteSecurityGroupRulesRequest to anchnet.
+func (c *Client) DeleteSecurityGroupRules(ctx context.Context, request *DeleteSecurityGroupRulesRequest) (*DeleteSecurityGroupRulesResponse, error) {
+	var response DeleteSecurityGroupRulesResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type DeleteSecurityGroupRulesResponse struct {
 	ResponseCommon `json:",inline"`
 	JobID          string `json:"job_id,omitempty"`
@@ -225,8 +332,59 @@ type ModifySecurityGroupRuleAttributesRequest struct {
 	Resources             []SecurityGroupResource    `json:"resource,omitempty"`
 }
 
+func (ModifySecurityGroupRuleAttributesRequest) ActionName() string { return "ModifySecurityGroupRuleAttributes" }
+
+// Validate implements Validator.
+func (r ModifySecurityGroupRuleAttributesRequest) Validate() error {
+	return requireID(r.ActionName(), "SecurityGroupRuleID", r.SecurityGroupRuleID)
+}
+
+// ModifySecurityGroupRuleAttributes sends ModifySecurityGroupRuleAttributesRequest to anchnet.
+func (c *Client) ModifySecurityGroupRuleAttributes(ctx context.Context, request *ModifySecurityGroupRuleAttributesRequest) (*ModifySecurityGroupRuleAttributesResponse, error) {
+	var response ModifySecurityGroupRuleAttributesResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type ModifySecurityGroupRuleAttributesResponse struct {
 	ResponseCommon      `json:",inline"`
 	SecurityGroupRuleID string `json:"security_group_rule_id,omitempty"`
 	JobID               string `json:"job_id,omitempty"`
 }
+
+// SecurityGroupPager pages through DescribeSecurityGroups results.
+type SecurityGroupPager struct {
+	pager
+	page []DescribeSecurityGroupsItem
+}
+
+// NewSecurityGroupPager creates a pager for security groups matching filter.
+func (c *Client) NewSecurityGroupPager(filter DescribeSecurityGroupsRequest) *SecurityGroupPager {
+	p := &SecurityGroupPager{}
+	p.pager = newPager(filter.Offset, filter.Limit, func(ctx context.Context, offset, limit int) (int, int, error) {
+		filter.Offset, filter.Limit = offset, limit
+		p.page = nil
+		response, err := c.DescribeSecurityGroups(ctx, &filter)
+		if err != nil {
+			return 0, 0, err
+		}
+		p.page = response.ItemSet
+		return len(response.ItemSet), response.TotalCount, nil
+	})
+	return p
+}
+
+// Page returns security groups in the page fetched by the last call to Next.
+func (p *SecurityGroupPager) Page() []DescribeSecurityGroupsItem {
+	return p.page
+}
+
+// ListAllSecurityGroups returns all security groups matching filter, following all pages.
+func (c *Client) ListAllSecurityGroups(ctx context.Context, filter DescribeSecurityGroupsRequest) ([]DescribeSecurityGroupsItem, error) {
+	var items []DescribeSecurityGroupsItem
+	p := c.NewSecurityGroupPager(filter)
+	for p.Next(ctx) {
+		items = append(items, p.Page()...)
+	}
+	return items, p.Err()
+}
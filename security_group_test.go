@@ -8,6 +8,9 @@ import (
 	"net/http/httptest"
 	"reflect"
 	"testing"
+	"time"
+
+	"github.com/caicloud/anchnet-go/testutil"
 )
 
 // TestDescribeSecurityGroups tests that we send correct request to describe security group.
@@ -22,7 +25,7 @@ func TestDescribeSecurityGroups(t *testing.T) {
 }
 `)
 
-	fakeResponse := RemoveWhitespaces(`
+	fakeResponse := `
 {
   "ret_code": 0,
   "action": "DescribeSecurityGroupsResponse",
@@ -90,9 +93,9 @@ func TestDescribeSecurityGroups(t *testing.T) {
   "code": 0,
   "total_count": 1
 }
-`)
+`
 
-	testServer := httptest.NewServer(&FakeHandler{t: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
+	testServer := httptest.NewServer(&testutil.FakeHandler{T: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
 	defer testServer.Close()
 
 	c, err := NewClient(testServer.URL, &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"})
@@ -126,7 +129,8 @@ func TestDescribeSecurityGroups(t *testing.T) {
 				SecurityGroupID:   "sg-BP4N974S",
 				SecurityGroupName: "default",
 				Description:       "51idc",
-				CreateTime:        "2015-03-0510:19:53",
+				CreateTime:        Time{time.Date(2015, 3, 5, 10, 19, 53, 0, location)},
+				Resources:         []SecurityGroupResource{},
 				SecurityGroupRules: []DescribeSecurityGroupRule{
 					{
 						SecurityGroupRuleID:   "sgr-0TZ05IH5",
@@ -210,7 +214,7 @@ func TestCreateSecurityGroup(t *testing.T) {
 }
 `)
 
-	testServer := httptest.NewServer(&FakeHandler{t: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
+	testServer := httptest.NewServer(&testutil.FakeHandler{T: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
 	defer testServer.Close()
 
 	c, err := NewClient(testServer.URL, &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"})
@@ -273,7 +277,7 @@ func TestDeleteSecurityGroups(t *testing.T) {
 }
 `)
 
-	testServer := httptest.NewServer(&FakeHandler{t: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
+	testServer := httptest.NewServer(&testutil.FakeHandler{T: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
 	defer testServer.Close()
 
 	c, err := NewClient(testServer.URL, &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"})
@@ -328,7 +332,7 @@ func TestApplySecurityGroup(t *testing.T) {
 }
 `)
 
-	testServer := httptest.NewServer(&FakeHandler{t: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
+	testServer := httptest.NewServer(&testutil.FakeHandler{T: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
 	defer testServer.Close()
 
 	c, err := NewClient(testServer.URL, &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"})
@@ -383,7 +387,7 @@ func TestModifySecurityGroupAttributes(t *testing.T) {
 }
 `)
 
-	testServer := httptest.NewServer(&FakeHandler{t: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
+	testServer := httptest.NewServer(&testutil.FakeHandler{T: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
 	defer testServer.Close()
 
 	c, err := NewClient(testServer.URL, &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"})
@@ -451,7 +455,7 @@ func TestDescribeSecurityGroupRules(t *testing.T) {
 }
 `)
 
-	testServer := httptest.NewServer(&FakeHandler{t: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
+	testServer := httptest.NewServer(&testutil.FakeHandler{T: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
 	defer testServer.Close()
 
 	c, err := NewClient(testServer.URL, &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"})
@@ -487,6 +491,7 @@ func TestDescribeSecurityGroupRules(t *testing.T) {
 				Value1:                "80",
 				Value2:                "90",
 				Value3:                "0.0.0.0",
+				SecurityGroupID:       "sg-D3T3CONW",
 			},
 		},
 	}
@@ -527,7 +532,7 @@ func TestAddSecurityGroupRules(t *testing.T) {
 }
 `)
 
-	testServer := httptest.NewServer(&FakeHandler{t: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
+	testServer := httptest.NewServer(&testutil.FakeHandler{T: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
 	defer testServer.Close()
 
 	c, err := NewClient(testServer.URL, &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"})
@@ -590,7 +595,7 @@ func TestDeleteSecurityGroupRules(t *testing.T) {
 }
 `)
 
-	testServer := httptest.NewServer(&FakeHandler{t: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
+	testServer := httptest.NewServer(&testutil.FakeHandler{T: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
 	defer testServer.Close()
 
 	c, err := NewClient(testServer.URL, &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"})
@@ -648,7 +653,7 @@ func TestModifySecurityGroupRuleAttributes(t *testing.T) {
 }
 `)
 
-	testServer := httptest.NewServer(&FakeHandler{t: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
+	testServer := httptest.NewServer(&testutil.FakeHandler{T: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
 	defer testServer.Close()
 
 	c, err := NewClient(testServer.URL, &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"})
@@ -0,0 +1,199 @@
+// Copyright 2015 anchnet-go authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package anchnet
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"gopkg.in/yaml.v2"
+)
+
+// RunInstancesSpec declares instances to run, along with their disks, networks,
+// eip and security group, e.g. in a YAML or JSON file kept in git:
+//   vm:
+//     name: worker
+//     cpu: 2
+//     mem: 4096
+//     image_id: trustysrvx64c
+//     keypair: kp-XXXXXXXX
+//   count: 3
+//   disks:
+//   - {name: data, type: 0, size: 100}
+//   vxnets:
+//   - name: private
+//   eip: {bandwidth: 1, ip_group: eipg-00000000}
+//   security_group: sg-XXXXXXXX
+// See ParseRunInstancesSpec, and Request for the RunInstancesRequest it describes.
+type RunInstancesSpec struct {
+	VM     RunInstancesVMSpec      `json:"vm"`
+	Count  int                     `json:"count,omitempty"` // Number of instances, default 1
+	Disks  []RunInstancesDiskSpec  `json:"disks,omitempty"`
+	Vxnets []RunInstancesVxnetSpec `json:"vxnets,omitempty"` // Private networks
+	// EIP is the public IP of the instances; nil means no public network.
+	EIP *RunInstancesEipSpec `json:"eip,omitempty"`
+	// SecurityGroupID is applied to the instances once they run. It's not part of
+	// RunInstancesRequest, see ApplySecurityGroupRequest.
+	SecurityGroupID string `json:"security_group,omitempty"`
+}
+
+// RunInstancesVMSpec declares the virtual machine. Exactly one of Password and
+// KeyPairID is required, and sets the login mode.
+type RunInstancesVMSpec struct {
+	Name      string `json:"name,omitempty"`
+	Cpu       int    `json:"cpu"`
+	Mem       int    `json:"mem"` // MB
+	ImageID   string `json:"image_id"`
+	Password  string `json:"password,omitempty"`
+	KeyPairID string `json:"keypair,omitempty"`
+	UserData  string `json:"user_data,omitempty"` // e.g. a cloud-init script
+}
+
+// RunInstancesDiskSpec declares either a new disk, or existing disks with IDs.
+type RunInstancesDiskSpec struct {
+	Name string   `json:"name,omitempty"`
+	Type HDType   `json:"type,omitempty"`
+	Size int      `json:"size,omitempty"` // GB
+	IDs  []string `json:"ids,omitempty"`
+}
+
+// RunInstancesVxnetSpec declares either a new private network, or existing ones
+// with IDs.
+type RunInstancesVxnetSpec struct {
+	Name string   `json:"name,omitempty"`
+	IDs  []string `json:"ids,omitempty"`
+}
+
+// RunInstancesEipSpec declares either a new eip, or an existing one with ID.
+type RunInstancesEipSpec struct {
+	ID        string      `json:"id,omitempty"`
+	Bandwidth int         `json:"bandwidth,omitempty"` // MB/s
+	IPGroup   IPGroupType `json:"ip_group,omitempty"`
+}
+
+// LoadRunInstancesSpec loads a spec file in YAML or JSON.
+func LoadRunInstancesSpec(path string) (*RunInstancesSpec, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseRunInstancesSpec(data)
+}
+
+// ParseRunInstancesSpec parses and validates a spec in YAML or JSON. Unknown
+// fields are rejected, so that typos don't go unnoticed.
+func ParseRunInstancesSpec(data []byte) (*RunInstancesSpec, error) {
+	// JSON is YAML, so both are parsed as YAML, then decoded as JSON to share the
+	// json tags and decoding of anchnet types.
+	var content interface{}
+	if err := yaml.Unmarshal(data, &content); err != nil {
+		return nil, fmt.Errorf("invalid instance spec: %v", err)
+	}
+	converted, err := json.Marshal(jsonValue(content))
+	if err != nil {
+		return nil, fmt.Errorf("invalid instance spec: %v", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(converted))
+	decoder.DisallowUnknownFields()
+	var spec RunInstancesSpec
+	if err := decoder.Decode(&spec); err != nil {
+		return nil, fmt.Errorf("invalid instance spec: %v", err)
+	}
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+	return &spec, nil
+}
+
+// jsonValue converts maps decoded from YAML, which have interface{} keys, to maps
+// with string keys, at any depth.
+func jsonValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(value))
+		for k, v := range value {
+			result[fmt.Sprint(k)] = jsonValue(v)
+		}
+		return result
+	case []interface{}:
+		for i, v := range value {
+			value[i] = jsonValue(v)
+		}
+	}
+	return value
+}
+
+// Validate checks the spec is consistent, then validates the request it describes.
+func (s *RunInstancesSpec) Validate() error {
+	switch {
+	case s.VM.Password != "" && s.VM.KeyPairID != "":
+		return specError("vm", "can't have both password and keypair")
+	case s.VM.Password == "" && s.VM.KeyPairID == "":
+		return specError("vm", "requires password or keypair")
+	case s.Count < 0:
+		return specError("count", "must not be negative, got %v", s.Count)
+	}
+	for i, disk := range s.Disks {
+		if len(disk.IDs) > 0 && (disk.Name != "" || disk.Size != 0) {
+			return specError(fmt.Sprintf("disks[%d]", i), "can't have both ids and name or size")
+		}
+	}
+	for i, vxnet := range s.Vxnets {
+		if (len(vxnet.IDs) > 0) == (vxnet.Name != "") {
+			return specError(fmt.Sprintf("vxnets[%d]", i), "requires either name or ids")
+		}
+	}
+	if s.EIP != nil && (s.EIP.ID != "") == (s.EIP.Bandwidth > 0) {
+		return specError("eip", "requires either id or bandwidth")
+	}
+	if err := s.Request().Validate(); err != nil {
+		return fmt.Errorf("invalid instance spec: %w", err)
+	}
+	return nil
+}
+
+func specError(field, format string, args ...interface{}) error {
+	return fmt.Errorf("invalid instance spec: %v %v", field, fmt.Sprintf(format, args...))
+}
+
+// Request returns the RunInstancesRequest described by the spec. SecurityGroupID
+// must be applied separately.
+func (s *RunInstancesSpec) Request() *RunInstancesRequest {
+	count := s.Count
+	if count == 0 {
+		count = 1
+	}
+	cloud := RunInstancesCloud{
+		VM: RunInstancesVM{
+			Name:      s.VM.Name,
+			LoginMode: LoginModePwd,
+			Mem:       s.VM.Mem,
+			Cpu:       s.VM.Cpu,
+			ImageID:   s.VM.ImageID,
+			Password:  s.VM.Password,
+			KeyPairID: s.VM.KeyPairID,
+		},
+		Amount: count,
+	}
+	if s.VM.KeyPairID != "" {
+		cloud.VM.LoginMode = LoginModeKeypair
+	}
+	if s.VM.UserData != "" {
+		cloud.VM.UserData = []byte(s.VM.UserData)
+	}
+	for _, disk := range s.Disks {
+		cloud.HD = append(cloud.HD, RunInstancesHardDisk{Name: disk.Name, Type: disk.Type, Unit: disk.Size, HdIDs: disk.IDs})
+	}
+	for _, vxnet := range s.Vxnets {
+		cloud.Net1 = append(cloud.Net1, RunInstancesNet1{VxnetName: vxnet.Name, Checked: vxnet.Name != "", VxnetIDs: vxnet.IDs})
+	}
+	if s.EIP != nil {
+		cloud.Net0 = true
+		cloud.IP = RunInstancesIP{EipID: s.EIP.ID, Bandwidth: s.EIP.Bandwidth, IPGroup: s.EIP.IPGroup}
+	}
+	return &RunInstancesRequest{Product: RunInstancesProduct{Cloud: cloud}}
+}
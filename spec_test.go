@@ -0,0 +1,125 @@
+// Copyright 2015 anchnet-go authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package anchnet
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestParseRunInstancesSpec tests that YAML and JSON specs describe the same request.
+func TestParseRunInstancesSpec(t *testing.T) {
+	yamlSpec := `
+vm:
+  name: worker
+  cpu: 2
+  mem: 4096
+  image_id: trustysrvx64c
+  keypair: kp-L4DLJM8W
+  user_data: |
+    #cloud-config
+    hostname: worker
+count: 3
+disks:
+- {name: data, type: 1, size: 100}
+- ids: [vol-46Q60KA1]
+vxnets:
+- name: private
+- ids: [vxnet-OXC1RD7G]
+eip: {bandwidth: 2, ip_group: eipg-00000000}
+security_group: sg-BP4N974S
+`
+	jsonSpec := `{
+  "vm": {"name": "worker", "cpu": 2, "mem": 4096, "image_id": "trustysrvx64c", "keypair": "kp-L4DLJM8W",
+         "user_data": "#cloud-config\nhostname: worker\n"},
+  "count": 3,
+  "disks": [{"name": "data", "type": "1", "size": 100}, {"ids": ["vol-46Q60KA1"]}],
+  "vxnets": [{"name": "private"}, {"ids": ["vxnet-OXC1RD7G"]}],
+  "eip": {"bandwidth": 2, "ip_group": "eipg-00000000"},
+  "security_group": "sg-BP4N974S"
+}`
+
+	expected := &RunInstancesRequest{
+		Product: RunInstancesProduct{
+			Cloud: RunInstancesCloud{
+				VM: RunInstancesVM{
+					Name:      "worker",
+					LoginMode: LoginModeKeypair,
+					Mem:       4096,
+					Cpu:       2,
+					ImageID:   "trustysrvx64c",
+					KeyPairID: "kp-L4DLJM8W",
+					UserData:  []byte("#cloud-config\nhostname: worker\n"),
+				},
+				HD: []RunInstancesHardDisk{
+					{Name: "data", Type: HDTypeCapacity, Unit: 100},
+					{HdIDs: []string{"vol-46Q60KA1"}},
+				},
+				Net0: true,
+				Net1: []RunInstancesNet1{
+					{VxnetName: "private", Checked: true},
+					{VxnetIDs: []string{"vxnet-OXC1RD7G"}},
+				},
+				IP:     RunInstancesIP{Bandwidth: 2, IPGroup: IPGroupBGP},
+				Amount: 3,
+			},
+		},
+	}
+	for _, data := range []string{yamlSpec, jsonSpec} {
+		spec, err := ParseRunInstancesSpec([]byte(data))
+		if err != nil {
+			t.Errorf("Unexpected non-nil error %v", err)
+			continue
+		}
+		if spec.SecurityGroupID != "sg-BP4N974S" {
+			t.Errorf("Expected security group sg-BP4N974S, got %v", spec.SecurityGroupID)
+		}
+		if request := spec.Request(); !reflect.DeepEqual(expected, request) {
+			t.Errorf("Error: expected \n%+v, got \n%+v", expected, request)
+		}
+	}
+
+	// Minimal spec: one instance without public network.
+	spec, err := ParseRunInstancesSpec([]byte(`vm: {cpu: 1, mem: 1024, image_id: trustysrvx64c, password: caicloud2015ABC}`))
+	if err != nil {
+		t.Fatalf("Unexpected non-nil error %v", err)
+	}
+	if cloud := spec.Request().Product.Cloud; cloud.Amount != 1 || cloud.Net0 || cloud.VM.LoginMode != LoginModePwd {
+		t.Errorf("Unexpected request %+v", cloud)
+	}
+}
+
+// TestParseInvalidRunInstancesSpec tests that invalid specs are rejected.
+func TestParseInvalidRunInstancesSpec(t *testing.T) {
+	vm := "vm: {cpu: 1, mem: 1024, image_id: trustysrvx64c, password: caicloud2015ABC}\n"
+	tests := []struct {
+		spec          string
+		expectedError string
+	}{
+		{"vm: [", "invalid instance spec: yaml"},
+		{vm + "disk: [{size: 10}]", `unknown field "disk"`},
+		{"vm: {cpu: 1, mem: 1024, image_id: trustysrvx64c}", "vm requires password or keypair"},
+		{"vm: {cpu: 1, mem: 1024, image_id: trustysrvx64c, password: x, keypair: kp-L4DLJM8W}", "vm can't have both password and keypair"},
+		{vm + "count: -1", "count must not be negative"},
+		{vm + "disks: [{ids: [vol-46Q60KA1], size: 10}]", "disks[0] can't have both ids and name or size"},
+		{vm + "vxnets: [{}]", "vxnets[0] requires either name or ids"},
+		{vm + "eip: {id: eip-TYFJDV7K, bandwidth: 1}", "eip requires either id or bandwidth"},
+		{vm + "disks: [{size: 5}]", "Product.Cloud.HD[0].Unit must be between 10 and 1000, got 5"},
+		{vm + `disks: [{type: "", size: 10}]`, "disk type should be a number, got empty string"},
+	}
+	for i, test := range tests {
+		_, err := ParseRunInstancesSpec([]byte(test.spec))
+		if err == nil || !strings.Contains(err.Error(), test.expectedError) {
+			t.Errorf("Test %d: expected error %q, got %v", i, test.expectedError, err)
+		}
+	}
+
+	// Errors of the request are validation errors.
+	_, err := ParseRunInstancesSpec([]byte("vm: {cpu: 3, mem: 1024, image_id: trustysrvx64c, password: x}"))
+	if _, ok := IsValidationError(err); !ok {
+		t.Errorf("Expected validation error, got %v", err)
+	}
+}
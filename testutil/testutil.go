@@ -0,0 +1,75 @@
+// Copyright 2015 anchnet-go authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package testutil provides helpers for unittest of anchnet clients: a fake http
+// handler, and assertions on request JSON. To replay recorded responses without
+// network, see package replay. It doesn't import the anchnet package, so tests of anchnet itself
+// can use it; it is only meant to be imported from tests.
+package testutil
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+// FakeHandler is a fake http handler, used in unittest. It checks every request
+// body against ExpectedJson, and replies with FakeResponse.
+type FakeHandler struct {
+	ExpectedJson string
+	FakeResponse string
+
+	T testing.TB
+}
+
+func (f *FakeHandler) ServeHTTP(response http.ResponseWriter, request *http.Request) {
+	body, _ := ioutil.ReadAll(request.Body)
+	AssertJSONEqual(f.T, f.ExpectedJson, string(body))
+	response.Write([]byte(f.FakeResponse))
+}
+
+// AssertJSONEqual reports an error on t if expected and actual are not the same
+// JSON value. Whitespaces and order of object keys don't matter.
+func AssertJSONEqual(t testing.TB, expected, actual string) {
+	t.Helper()
+	var expect, got interface{}
+	if err := json.Unmarshal([]byte(expected), &expect); err != nil {
+		t.Errorf("Error: unexpected error unmarshaling expected json: %v", err)
+		return
+	}
+	if err := json.Unmarshal([]byte(actual), &got); err != nil {
+		t.Errorf("Error: unexpected error unmarshaling request body: %v", err)
+		return
+	}
+	if !reflect.DeepEqual(expect, got) {
+		t.Errorf("Error: expected \n%v, got \n%v", expect, got)
+	}
+}
+
+// AssertJSONFields reports an error on t if JSON object actual doesn't have all
+// fields in expected, with the same values. Other fields of actual are ignored,
+// e.g. token and zone in a request.
+func AssertJSONFields(t testing.TB, expected map[string]interface{}, actual string) {
+	t.Helper()
+	var got map[string]interface{}
+	if err := json.Unmarshal([]byte(actual), &got); err != nil {
+		t.Errorf("Error: unexpected error unmarshaling request body: %v", err)
+		return
+	}
+	// Round trip expected values, so that e.g. ints compare equal to float64s.
+	buf, err := json.Marshal(expected)
+	if err != nil {
+		t.Errorf("Error: unexpected error marshaling expected fields: %v", err)
+		return
+	}
+	var expect map[string]interface{}
+	json.Unmarshal(buf, &expect)
+	for key, value := range expect {
+		if !reflect.DeepEqual(value, got[key]) {
+			t.Errorf("Error: expected %v to be \n%v, got \n%v", key, value, got[key])
+		}
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright 2015 anchnet-go authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package testutil
+
+import (
+	"testing"
+)
+
+// recorder is a testing.TB which records errors.
+type recorder struct {
+	testing.TB
+	errors int
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors++
+}
+
+// TestAssertJSON tests that JSON assertions catch mismatches.
+func TestAssertJSON(t *testing.T) {
+	tests := []struct {
+		assert         func(t testing.TB)
+		expectedErrors int
+	}{
+		{func(t testing.TB) { AssertJSONEqual(t, `{"a": 1, "b": [1, 2]}`, `{"b":[1,2],"a":1}`) }, 0},
+		{func(t testing.TB) { AssertJSONEqual(t, `{"a": 1}`, `{"a": 2}`) }, 1},
+		{func(t testing.TB) { AssertJSONEqual(t, `{"a": 1}`, `not json`) }, 1},
+		{func(t testing.TB) { AssertJSONFields(t, map[string]interface{}{"a": 1}, `{"a": 1, "token": "x"}`) }, 0},
+		{func(t testing.TB) { AssertJSONFields(t, map[string]interface{}{"a": 1, "b": "x"}, `{"a": 2}`) }, 2},
+	}
+	for i, test := range tests {
+		r := &recorder{TB: t}
+		test.assert(r)
+		if r.errors != test.expectedErrors {
+			t.Errorf("Test %d: expected %d errors, got %d", i, test.expectedErrors, r.errors)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2015 anchnet-go authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package anchnet
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// TimeFormat is the format of timestamps in anchnet responses.
+const TimeFormat = "2006-01-02 15:04:05"
+
+// dateFormat is used by anchnet for some timestamps, e.g. vxnet create time.
+const dateFormat = "2006-01-02"
+
+// location is the timezone of anchnet timestamps, China Standard Time.
+var location = time.FixedZone("CST", 8*60*60)
+
+// Time is a timestamp in anchnet responses, e.g. CreateTime, in TimeFormat and in
+// China Standard Time. Empty timestamps are decoded as zero Time.
+type Time struct {
+	time.Time
+}
+
+// NewTime returns t as an anchnet Time, in China Standard Time.
+func NewTime(t time.Time) Time {
+	return Time{Time: t.In(location)}
+}
+
+// UnmarshalJSON decodes a timestamp string, which can be empty.
+func (t *Time) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("anchnet time should be a string, got %s", data)
+	}
+	if s == "" {
+		*t = Time{}
+		return nil
+	}
+	parsed, err := time.ParseInLocation(TimeFormat, s, location)
+	if err != nil {
+		var dateErr error
+		if parsed, dateErr = time.ParseInLocation(dateFormat, s, location); dateErr != nil {
+			return err
+		}
+	}
+	t.Time = parsed
+	return nil
+}
+
+// MarshalJSON encodes t in TimeFormat, or as null if t is zero, since omitempty
+// doesn't apply to structs.
+func (t Time) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(t.In(location).Format(TimeFormat))
+}
+
+// String returns t in TimeFormat.
+func (t Time) String() string {
+	return t.In(location).Format(TimeFormat)
+}
@@ -0,0 +1,53 @@
+// Copyright 2015 anchnet-go authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package anchnet
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// TestTime tests decoding and encoding of anchnet timestamps.
+func TestTime(t *testing.T) {
+	tests := []struct {
+		data     string
+		expected Time
+	}{
+		{`"2015-02-15 11:10:37"`, Time{time.Date(2015, 2, 15, 11, 10, 37, 0, location)}},
+		{`"2015-03-24"`, Time{time.Date(2015, 3, 24, 0, 0, 0, 0, location)}},
+		{`""`, Time{}},
+		{`null`, Time{}},
+	}
+	for i, test := range tests {
+		var actual Time
+		if err := json.Unmarshal([]byte(test.data), &actual); err != nil {
+			t.Errorf("Test %d: unexpected non-nil error %v", i, err)
+		}
+		if !actual.Equal(test.expected.Time) {
+			t.Errorf("Test %d: expected %v, got %v", i, test.expected, actual)
+		}
+	}
+
+	for _, data := range []string{`"2015/02/15"`, `1423970437`} {
+		var actual Time
+		if err := json.Unmarshal([]byte(data), &actual); err == nil {
+			t.Errorf("Expected error decoding %v", data)
+		}
+	}
+
+	// Timestamps are in China Standard Time.
+	created := NewTime(time.Date(2015, 2, 15, 3, 10, 37, 0, time.UTC))
+	data, err := json.Marshal(created)
+	if err != nil {
+		t.Errorf("Unexpected non-nil error %v", err)
+	}
+	if string(data) != `"2015-02-15 11:10:37"` {
+		t.Errorf("Expected 2015-02-15 11:10:37, got %s", data)
+	}
+	if data, _ = json.Marshal(Time{}); string(data) != "null" {
+		t.Errorf("Expected null, got %s", data)
+	}
+}
@@ -4,6 +4,10 @@
 
 package anchnet
 
+import (
+	"context"
+)
+
 // Implements anchnet user project related APIs
 
 //
@@ -20,6 +24,23 @@ type CreateUserProjectRequest struct {
 	LoginPasswd   string `json:"loginPasswd,omitempty"`
 }
 
+func (CreateUserProjectRequest) ActionName() string { return "CreateUserProject" }
+
+// Validate implements Validator.
+func (r CreateUserProjectRequest) Validate() error {
+	return firstError(
+		requireID(r.ActionName(), "LoginID", r.LoginID),
+		requireID(r.ActionName(), "LoginPasswd", r.LoginPasswd),
+	)
+}
+
+// CreateUserProject sends CreateUserProjectRequest to anchnet.
+func (c *Client) CreateUserProject(ctx context.Context, request *CreateUserProjectRequest) (*CreateUserProjectResponse, error) {
+	var response CreateUserProjectResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type CreateUserProjectResponse struct {
 	ResponseCommon `json:",inline"`
 	ApiID          string `json:"api_id,omitempty"`
@@ -35,6 +56,15 @@ type DescribeProjectsRequest struct {
 	SearchWord    string `json:"search_word,omitempty"`
 }
 
+func (DescribeProjectsRequest) ActionName() string { return "DescribeProjects" }
+
+// DescribeProjects sends DescribeProjectsRequest to anchnet.
+func (c *Client) DescribeProjects(ctx context.Context, request *DescribeProjectsRequest) (*DescribeProjectsResponse, error) {
+	var response DescribeProjectsResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type DescribeProjectsResponse struct {
 	ResponseCommon `json:",inline"`
 	ItemSet        []DescribeProjectsItem `json:"item_set,omitempty"`
@@ -54,9 +84,9 @@ type DescribeProjectsItem struct {
 }
 
 type DescribeProjectsBalance struct {
-	Value   string  `json:"value,omitempty"`
-	Coupon  string  `json:"coupon,omitempty"`
-	Consume float64 `json:"consume,omitempty"`
+	Value   Float64 `json:"value,omitempty"`
+	Coupon  Float64 `json:"coupon,omitempty"`
+	Consume Float64 `json:"consume,omitempty"`
 }
 
 //
@@ -73,6 +103,24 @@ type TransferRequest struct {
 	Why    string `json:"why,omitempty"`
 }
 
+func (TransferRequest) ActionName() string { return "Transfer" }
+
+// Validate implements Validator.
+func (r TransferRequest) Validate() error {
+	err := requireID(r.ActionName(), "Value", r.Value)
+	if err == nil && r.UserID <= 0 {
+		err = invalid(r.ActionName(), "UserID", "is required")
+	}
+	return err
+}
+
+// Transfer sends TransferRequest to anchnet.
+func (c *Client) Transfer(ctx context.Context, request *TransferRequest) (*TransferResponse, error) {
+	var response TransferResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type TransferResponse struct {
 	ResponseCommon `json:",inline"`
 }
@@ -87,6 +135,15 @@ type DescribeUsersRequest struct {
 	SearchWord string `json:"search_word,omitempty"`
 }
 
+func (DescribeUsersRequest) ActionName() string { return "DescribeUsers" }
+
+// DescribeUsers sends DescribeUsersRequest to anchnet.
+func (c *Client) DescribeUsers(ctx context.Context, request *DescribeUsersRequest) (*DescribeUsersResponse, error) {
+	var response DescribeUsersResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type DescribeUsersResponse struct {
 	ResponseCommon `json:",inline"`
 	ItemSet        []DescribeUsersItem `json:"item_set,omitempty"`
@@ -104,6 +161,15 @@ type GetChargeSummaryRequest struct {
 	RequestCommon `json:",inline"`
 }
 
+func (GetChargeSummaryRequest) ActionName() string { return "GetChargeSummary" }
+
+// GetChargeSummary sends GetChargeSummaryRequest to anchnet.
+func (c *Client) GetChargeSummary(ctx context.Context, request *GetChargeSummaryRequest) (*GetChargeSummaryResponse, error) {
+	var response GetChargeSummaryResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type GetChargeSummaryResponse struct {
 	ResponseCommon `json:",inline"`
 	TotalSum       string                 `json:"total_sum,omitempty"`
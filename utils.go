@@ -8,13 +8,9 @@ import (
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
-	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"net/http"
 	"reflect"
 	"regexp"
-	"testing"
 )
 
 // RemoveWhitespaces removes all white spaces from a string, return a new string.
@@ -124,28 +120,3 @@ func deepCopy(src reflect.Value) (reflect.Value, error) {
 		return src, nil
 	}
 }
-
-// FakeHandler is a fake http handler, used in unittest.
-type FakeHandler struct {
-	ExpectedJson string
-	FakeResponse string
-
-	t *testing.T
-}
-
-func (f *FakeHandler) ServeHTTP(response http.ResponseWriter, request *http.Request) {
-	body, _ := ioutil.ReadAll(request.Body)
-	var expect, actual map[string]interface{}
-	err := json.Unmarshal([]byte(f.ExpectedJson), &expect)
-	if err != nil {
-		f.t.Errorf("Error: unexpected error unmarshaling expected json: %v", err)
-	}
-	err = json.Unmarshal(body, &actual)
-	if err != nil {
-		f.t.Errorf("Error: unexpected error unmarshaling request body: %v", err)
-	}
-	if !reflect.DeepEqual(expect, actual) {
-		f.t.Errorf("Error: expected \n%v, got \n%v", expect, actual)
-	}
-	response.Write([]byte(f.FakeResponse))
-}
@@ -0,0 +1,93 @@
+// Copyright 2015 anchnet-go authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package anchnet
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Validator is implemented by requests which can be checked before they are sent,
+// e.g. for missing IDs, unknown enum values or sizes out of range. SendRequest
+// validates requests unless Client.SkipValidation is set. Validation only catches
+// mistakes obvious without asking anchnet; passing it doesn't mean anchnet will
+// accept the request.
+type Validator interface {
+	Validate() error
+}
+
+// ValidationError is returned by Validate, and by SendRequest for invalid requests.
+type ValidationError struct {
+	Action string // Action of the request, e.g. RunInstances
+	Field  string // Path of the invalid field, e.g. Product.Cloud.VM.Mem
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid %v request: %v %v", e.Action, e.Field, e.Reason)
+}
+
+// IsValidationError returns the ValidationError in err's chain, if any.
+func IsValidationError(err error) (*ValidationError, bool) {
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		return validationErr, true
+	}
+	return nil, false
+}
+
+// Following helpers check a field of a request of action, returning a
+// *ValidationError or nil.
+
+func invalid(action, field, format string, args ...interface{}) error {
+	return &ValidationError{Action: action, Field: field, Reason: fmt.Sprintf(format, args...)}
+}
+
+func requireID(action, field, id string) error {
+	if id == "" {
+		return invalid(action, field, "is required")
+	}
+	return nil
+}
+
+func requireIDs(action, field string, ids []string) error {
+	if len(ids) == 0 {
+		return invalid(action, field, "is required")
+	}
+	for i, id := range ids {
+		if id == "" {
+			return invalid(action, fmt.Sprintf("%v[%d]", field, i), "is empty")
+		}
+	}
+	return nil
+}
+
+func requireRange(action, field string, value, min, max int) error {
+	if value < min || value > max {
+		return invalid(action, field, "must be between %v and %v, got %v", min, max, value)
+	}
+	return nil
+}
+
+// requireOneOf checks value is one of choices, which must have the same type.
+func requireOneOf(action, field string, value interface{}, choices ...interface{}) error {
+	for _, choice := range choices {
+		if value == choice {
+			return nil
+		}
+	}
+	return invalid(action, field, "must be one of %v, got %v", choices, value)
+}
+
+// firstError returns the first non-nil error, so that checks can be listed in one
+// return statement.
+func firstError(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
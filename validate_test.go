@@ -0,0 +1,204 @@
+// Copyright 2015 anchnet-go authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package anchnet
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+// TestValidate tests validation of request fields.
+func TestValidate(t *testing.T) {
+	vm := RunInstancesVM{Name: "test", LoginMode: LoginModePwd, Password: "caicloud2015ABC", Mem: 1024, Cpu: 1, ImageID: "trustysrvx64c"}
+	run := func(modify func(cloud *RunInstancesCloud)) RunInstancesRequest {
+		request := RunInstancesRequest{Product: RunInstancesProduct{Cloud: RunInstancesCloud{VM: vm}}}
+		modify(&request.Product.Cloud)
+		return request
+	}
+
+	tests := []struct {
+		request       Validator
+		expectedError *ValidationError
+	}{
+		{
+			request: run(func(cloud *RunInstancesCloud) {
+				cloud.HD = []RunInstancesHardDisk{{Type: HDTypeCapacity, Unit: 100}, {HdIDs: []string{"vol-46Q60KA1"}}}
+				cloud.Net0 = true
+				cloud.Net1 = []RunInstancesNet1{{VxnetName: "private", Checked: true}, {VxnetIDs: []string{"vxnet-OXC1RD7G"}}}
+				cloud.IP = RunInstancesIP{Bandwidth: 1, IPGroup: IPGroupBGP}
+			}),
+		},
+		{
+			request:       run(func(cloud *RunInstancesCloud) { cloud.VM.Mem = 3000 }),
+			expectedError: &ValidationError{Action: "RunInstances", Field: "Product.Cloud.VM.Mem", Reason: "must be one of [1024 2048 4096 8192 16384 32768], got 3000"},
+		},
+		{
+			request:       run(func(cloud *RunInstancesCloud) { cloud.VM.Cpu = 3 }),
+			expectedError: &ValidationError{Action: "RunInstances", Field: "Product.Cloud.VM.Cpu", Reason: "must be one of [1 2 4 8], got 3"},
+		},
+		{
+			request:       run(func(cloud *RunInstancesCloud) { cloud.VM.Password = "" }),
+			expectedError: &ValidationError{Action: "RunInstances", Field: "Product.Cloud.VM.Password", Reason: "is required in login mode pwd"},
+		},
+		{
+			request:       run(func(cloud *RunInstancesCloud) { cloud.VM.LoginMode, cloud.VM.Password = LoginModeKeypair, "" }),
+			expectedError: &ValidationError{Action: "RunInstances", Field: "Product.Cloud.VM.KeyPairID", Reason: "is required in login mode keypair"},
+		},
+		{
+			request:       run(func(cloud *RunInstancesCloud) { cloud.VM.LoginMode = "" }),
+			expectedError: &ValidationError{Action: "RunInstances", Field: "Product.Cloud.VM.LoginMode", Reason: "must be one of [pwd keypair], got "},
+		},
+		{
+			request:       CreateKeyPairRequest{KeyPairName: "deploy", Mode: KeyPairModeUser},
+			expectedError: &ValidationError{Action: "CreateKeyPair", Field: "PublicKey", Reason: "is required"},
+		},
+		{
+			request:       run(func(cloud *RunInstancesCloud) { cloud.HD = []RunInstancesHardDisk{{Type: 2, Unit: 10}} }),
+			expectedError: &ValidationError{Action: "RunInstances", Field: "Product.Cloud.HD[0].Type", Reason: "must be one of [0 1], got 2"},
+		},
+		{
+			request:       run(func(cloud *RunInstancesCloud) { cloud.HD = []RunInstancesHardDisk{{Unit: 5}} }),
+			expectedError: &ValidationError{Action: "RunInstances", Field: "Product.Cloud.HD[0].Unit", Reason: "must be between 10 and 1000, got 5"},
+		},
+		{
+			request:       run(func(cloud *RunInstancesCloud) { cloud.IP = RunInstancesIP{EipID: "eip-TYFJDV7K"} }),
+			expectedError: &ValidationError{Action: "RunInstances", Field: "Product.Cloud.Net0", Reason: "must be set to use IP"},
+		},
+		{
+			request:       StopInstancesRequest{InstanceIDs: []string{"i-G74Q69NJ", ""}},
+			expectedError: &ValidationError{Action: "StopInstances", Field: "InstanceIDs[1]", Reason: "is empty"},
+		},
+		{
+			request:       AttachVolumesRequest{VolumeIDs: []string{"vol-46Q60KA1"}},
+			expectedError: &ValidationError{Action: "AttachVolumes", Field: "InstanceID", Reason: "is required"},
+		},
+		{
+			request:       CreateVolumesRequest{VolumeType: VolumeTypeCapacity, Size: 2000},
+			expectedError: &ValidationError{Action: "CreateVolumes", Field: "Size", Reason: "must be between 10 and 1000, got 2000"},
+		},
+		{
+			request:       CreateVolumesRequest{VolumeType: "2", Size: 20},
+			expectedError: &ValidationError{Action: "CreateVolumes", Field: "VolumeType", Reason: "must be one of [0 1], got 2"},
+		},
+		{
+			request:       CreateVxnetsRequest{VxnetType: 3},
+			expectedError: &ValidationError{Action: "CreateVxnets", Field: "VxnetType", Reason: "must be one of [0 1], got 3"},
+		},
+		{
+			request:       CreateLoadBalancerRequest{},
+			expectedError: &ValidationError{Action: "CreateLoadBalancer", Field: "Product.Loadbalancer.Type", Reason: "must be one of [1 2 3], got 0"},
+		},
+		{
+			request: AddLoadBalancerListenersRequest{
+				LoadbalancerID: "lb-3KJD8NR2",
+				Listeners:      []AddLoadBalancerListenersListener{{ListenerOptions: ListenerOptions{ListenerPort: 80, BalanceMode: "random"}}},
+			},
+			expectedError: &ValidationError{Action: "AddLoadBalancerListeners", Field: "Listeners[0].BalanceMode", Reason: "must be one of [roundrobin leastconn source], got random"},
+		},
+		{
+			request:       ModifyLoadBalancerListenerAttributesRequest{ListenerID: "lbl-4U9Y4ZZX", ListenerOptions: ListenerOptions{BalanceMode: BalanceModeSource}},
+			expectedError: nil,
+		},
+		{
+			request:       ResizeInstancesRequest{InstanceIDs: []string{"i-G74Q69NJ"}},
+			expectedError: &ValidationError{Action: "ResizeInstances", Field: "Cpu", Reason: "or Mem is required"},
+		},
+		{
+			request:       ResizeInstancesRequest{InstanceIDs: []string{"i-G74Q69NJ"}, Mem: 3000},
+			expectedError: &ValidationError{Action: "ResizeInstances", Field: "Mem", Reason: "must be one of [1024 2048 4096 8192 16384 32768], got 3000"},
+		},
+		{
+			request:       ResizeVolumesRequest{VolumeIDs: []string{"vol-46Q60KA1"}, Size: 1001},
+			expectedError: &ValidationError{Action: "ResizeVolumes", Field: "Size", Reason: "must be between 10 and 1000, got 1001"},
+		},
+	}
+
+	for i, test := range tests {
+		err := test.request.Validate()
+		if test.expectedError == nil && err != nil {
+			t.Errorf("Test %d: unexpected non-nil error %v", i, err)
+		}
+		if test.expectedError != nil && !reflect.DeepEqual(test.expectedError, err) {
+			t.Errorf("Test %d: expected error \n%v, got \n%v", i, test.expectedError, err)
+		}
+	}
+}
+
+// TestSendInvalidRequest tests that invalid requests are not sent, unless
+// validation is skipped.
+func TestSendInvalidRequest(t *testing.T) {
+	var requests int32
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Write([]byte(`{"ret_code": 0, "code": 0}`))
+	}))
+	defer testServer.Close()
+
+	c, err := NewClient(testServer.URL, &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"})
+	if err != nil {
+		t.Errorf("Unexpected non-nil error %v", err)
+	}
+
+	var response StartInstancesResponse
+	err = c.SendRequest(&StartInstancesRequest{}, &response)
+	if _, ok := IsValidationError(err); !ok {
+		t.Errorf("Expected validation error, got %v", err)
+	}
+	if requests != 0 {
+		t.Errorf("Expected no request sent, got %d", requests)
+	}
+
+	c.SkipValidation = true
+	if err = c.SendRequest(&StartInstancesRequest{}, &response); err != nil {
+		t.Errorf("Unexpected non-nil error %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("Expected request sent, got %d", requests)
+	}
+}
+
+// TestResizeVolumesGrow tests that volumes can't be shrunk.
+func TestResizeVolumesGrow(t *testing.T) {
+	var resized bool
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		var common RequestCommon
+		json.Unmarshal(body, &common)
+		switch common.Action {
+		case "DescribeVolumes":
+			w.Write([]byte(`{"ret_code":0,"code":0,"total_count":2,"item_set":[{"volume_id":"vol-46Q60KA1","size":"20","volume_type":"0"},{"volume_id":"vol-EAWEJ5RI","size":"50","volume_type":"0"}]}`))
+		case "ResizeVolumes":
+			resized = true
+			w.Write([]byte(`{"ret_code":0,"code":0,"job_id":"job-OZNXSPZO"}`))
+		}
+	}))
+	defer testServer.Close()
+
+	c, err := NewClient(testServer.URL, &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"})
+	if err != nil {
+		t.Errorf("Unexpected non-nil error %v", err)
+	}
+
+	request := &ResizeVolumesRequest{VolumeIDs: []string{"vol-46Q60KA1", "vol-EAWEJ5RI"}, Size: 30}
+	_, err = c.ResizeVolumes(context.Background(), request)
+	expectedError := &ValidationError{Action: "ResizeVolumes", Field: "Size", Reason: "must be greater than 50GB of volume vol-EAWEJ5RI, got 30"}
+	if !reflect.DeepEqual(expectedError, err) {
+		t.Errorf("Expected error \n%v, got \n%v", expectedError, err)
+	}
+	if resized {
+		t.Errorf("Expected volumes not resized")
+	}
+
+	request.Size = 60
+	if _, err = c.ResizeVolumes(context.Background(), request); err != nil || !resized {
+		t.Errorf("Expected volumes resized, got error %v", err)
+	}
+}
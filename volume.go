@@ -4,6 +4,12 @@
 
 package anchnet
 
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
 // Implements all anchnet instance related APIs.
 
 //
@@ -18,6 +24,15 @@ type DescribeVolumesRequest struct {
 	Limit         int            `json:"limit,omitempty"`
 }
 
+func (DescribeVolumesRequest) ActionName() string { return "DescribeVolumes" }
+
+// DescribeVolumes sends DescribeVolumesRequest to anchnet.
+func (c *Client) DescribeVolumes(ctx context.Context, request *DescribeVolumesRequest) (*DescribeVolumesResponse, error) {
+	var response DescribeVolumesResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type DescribeVolumesResponse struct {
 	ResponseCommon `json:",inline"`
 	TotalCount     int                   `json:"total_count,omitempty"`
@@ -29,11 +44,11 @@ type DescribeVolumesItem struct {
 	VolumeName  string                  `json:"volume_name,omitempty"`
 	Description string                  `json:"description,omitempty"`
 	Device      string                  `json:"device,omitempty"`
-	Size        string                  `json:"size,omitempty"` // Unit: GB
+	Size        Int                     `json:"size,omitempty"` // Unit: GB
 	Status      VolumeStatus            `json:"status,omitempty"`
-	StatusTime  string                  `json:"status_time,omitempty"`
+	StatusTime  Time                    `json:"status_time,omitempty"`
 	VolumeType  VolumeType              `json:"volume_type"`
-	CreateTime  string                  `json:"create_time,omitempty"`
+	CreateTime  Time                    `json:"create_time,omitempty"`
 	Instance    DescribeVolumesInstance `json:"instance,omitempty"`
 }
 
@@ -50,6 +65,13 @@ const (
 	VolumeStatusInUse     VolumeStatus = "in-use"
 	VolumeStatusSuspended VolumeStatus = "suspended"
 	VolumeStatusDeleted   VolumeStatus = "deleted"
+	VolumeStatusCeased    VolumeStatus = "ceased"
+)
+
+// Limits of volume size, in GB.
+const (
+	MinVolumeSize = 10
+	MaxVolumeSize = 1000
 )
 
 // Note VolumeType is the same as HDType.
@@ -60,6 +82,35 @@ const (
 	VolumeTypeCapacity    VolumeType = "1"
 )
 
+// UnmarshalJSON decodes a volume type, which is a string in DescribeVolumes but may
+// be a number like HDType. Empty string is kept as is, not taken for type "0".
+func (t *VolumeType) UnmarshalJSON(data []byte) error {
+	value, set, err := unmarshalDiskType(data)
+	switch {
+	case err != nil:
+		return err
+	case set:
+		*t = VolumeType(strconv.Itoa(value))
+	case string(data) == `""`:
+		*t = ""
+	}
+	return nil
+}
+
+// unmarshalDiskType decodes a disk type, i.e. VolumeType or HDType, from a number
+// or a string. set is false if data is null or empty string, which are not taken
+// for type 0.
+func unmarshalDiskType(data []byte) (value int, set bool, err error) {
+	if string(data) == "null" || string(data) == `""` {
+		return 0, false, nil
+	}
+	var i Int
+	if err := i.UnmarshalJSON(data); err != nil {
+		return 0, false, fmt.Errorf("anchnet disk type should be a number, got %s", data)
+	}
+	return int(i), true, nil
+}
+
 //
 // CreateVolumes creates given number of volumes.
 //
@@ -71,6 +122,23 @@ type CreateVolumesRequest struct {
 	Count         int        `json:"count,omitempty"`
 }
 
+func (CreateVolumesRequest) ActionName() string { return "CreateVolumes" }
+
+// Validate implements Validator.
+func (r CreateVolumesRequest) Validate() error {
+	return firstError(
+		requireOneOf(r.ActionName(), "VolumeType", r.VolumeType, VolumeTypePerformance, VolumeTypeCapacity),
+		requireRange(r.ActionName(), "Size", r.Size, MinVolumeSize, MaxVolumeSize),
+	)
+}
+
+// CreateVolumes sends CreateVolumesRequest to anchnet.
+func (c *Client) CreateVolumes(ctx context.Context, request *CreateVolumesRequest) (*CreateVolumesResponse, error) {
+	var response CreateVolumesResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type CreateVolumesResponse struct {
 	ResponseCommon `json:",inline"`
 	VolumeIDs      []string `json:"volumes,omitempty"` // IDs of created volumes
@@ -85,6 +153,20 @@ type DeleteVolumesRequest struct {
 	VolumeIDs     []string `json:"volumes,omitempty"`
 }
 
+func (DeleteVolumesRequest) ActionName() string { return "DeleteVolumes" }
+
+// Validate implements Validator.
+func (r DeleteVolumesRequest) Validate() error {
+	return requireIDs(r.ActionName(), "VolumeIDs", r.VolumeIDs)
+}
+
+// DeleteVolumes sends DeleteVolumesRequest to anchnet.
+func (c *Client) DeleteVolumes(ctx context.Context, request *DeleteVolumesRequest) (*DeleteVolumesResponse, error) {
+	var response DeleteVolumesResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type DeleteVolumesResponse struct {
 	ResponseCommon `json:",inline"`
 	JobID          string `json:"job_id,omitempty"` // Job ID in anchnet
@@ -99,6 +181,23 @@ type AttachVolumesRequest struct {
 	VolumeIDs     []string `json:"volumes,omitempty"`  // IDs of volumes
 }
 
+func (AttachVolumesRequest) ActionName() string { return "AttachVolumes" }
+
+// Validate implements Validator.
+func (r AttachVolumesRequest) Validate() error {
+	return firstError(
+		requireID(r.ActionName(), "InstanceID", r.InstanceID),
+		requireIDs(r.ActionName(), "VolumeIDs", r.VolumeIDs),
+	)
+}
+
+// AttachVolumes sends AttachVolumesRequest to anchnet.
+func (c *Client) AttachVolumes(ctx context.Context, request *AttachVolumesRequest) (*AttachVolumesResponse, error) {
+	var response AttachVolumesResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type AttachVolumesResponse struct {
 	ResponseCommon `json:",inline"`
 	JobID          string `json:"job_id,omitempty"`
@@ -112,6 +211,20 @@ type DetachVolumesRequest struct {
 	VolumeIDs     []string `json:"volumes,omitempty"` // IDs of volumes to detach
 }
 
+func (DetachVolumesRequest) ActionName() string { return "DetachVolumes" }
+
+// Validate implements Validator.
+func (r DetachVolumesRequest) Validate() error {
+	return requireIDs(r.ActionName(), "VolumeIDs", r.VolumeIDs)
+}
+
+// DetachVolumes sends DetachVolumesRequest to anchnet.
+func (c *Client) DetachVolumes(ctx context.Context, request *DetachVolumesRequest) (*DetachVolumesResponse, error) {
+	var response DetachVolumesResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type DetachVolumesResponse struct {
 	ResponseCommon `json:",inline"`
 	JobID          string `json:"job_id,omitempty"`
@@ -126,6 +239,37 @@ type ResizeVolumesRequest struct {
 	Size          int      `json:"size,omitempty"`    // Allow increase size only
 }
 
+func (ResizeVolumesRequest) ActionName() string { return "ResizeVolumes" }
+
+// Validate implements Validator. It can't check that volumes grow, since current
+// sizes are unknown; Client.ResizeVolumes does.
+func (r ResizeVolumesRequest) Validate() error {
+	return firstError(
+		requireIDs(r.ActionName(), "VolumeIDs", r.VolumeIDs),
+		requireRange(r.ActionName(), "Size", r.Size, MinVolumeSize, MaxVolumeSize),
+	)
+}
+
+// ResizeVolumes sends ResizeVolumesRequest to anchnet. Unless c.SkipValidation is
+// set, it first describes the volumes, and returns a *ValidationError if any of
+// them would not grow.
+func (c *Client) ResizeVolumes(ctx context.Context, request *ResizeVolumesRequest) (*ResizeVolumesResponse, error) {
+	var response ResizeVolumesResponse
+	if !c.SkipValidation && request != nil && request.Validate() == nil {
+		volumes, err := c.ListAllVolumes(ctx, DescribeVolumesRequest{VolumeIDs: request.VolumeIDs})
+		if err != nil {
+			return &response, err
+		}
+		for _, item := range volumes {
+			if size := int(item.Size); request.Size <= size {
+				return &response, invalid(request.ActionName(), "Size", "must be greater than %vGB of volume %v, got %v", size, item.VolumeID, request.Size)
+			}
+		}
+	}
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type ResizeVolumesResponse struct {
 	ResponseCommon `json:",inline"`
 	JobID          string `json:"job_id,omitempty"`
@@ -141,7 +285,76 @@ type ModifyVolumeAttributesRequest struct {
 	Description   string `json:"description,omitempty"`
 }
 
+func (ModifyVolumeAttributesRequest) ActionName() string { return "ModifyVolumeAttributes" }
+
+// Validate implements Validator.
+func (r ModifyVolumeAttributesRequest) Validate() error {
+	return requireID(r.ActionName(), "VolumeID", r.VolumeID)
+}
+
+// ModifyVolumeAttributes sends ModifyVolumeAttributesRequest to anchnet.
+func (c *Client) ModifyVolumeAttributes(ctx context.Context, request *ModifyVolumeAttributesRequest) (*ModifyVolumeAttributesResponse, error) {
+	var response ModifyVolumeAttributesResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type ModifyVolumeAttributesResponse struct {
 	ResponseCommon `json:",inline"`
 	JobID          string `json:"job_id,omitempty"`
 }
+
+// WaitVolumeStatus waits until all volumes reach status, e.g. in-use, listing them
+// with ListAllVolumes on each poll. It returns a *StatusError as soon as any of them
+// becomes suspended, deleted or ceased instead, or is not found. opts can be nil.
+func (c *Client) WaitVolumeStatus(ctx context.Context, ids []string, status VolumeStatus, opts *WaitOptions) error {
+	terminal := []string{string(VolumeStatusSuspended), string(VolumeStatusDeleted), string(VolumeStatusCeased)}
+	return waitStatus(ctx, ids, string(status), terminal, opts, func(ctx context.Context, ids []string) (map[string]string, error) {
+		items, err := c.ListAllVolumes(ctx, DescribeVolumesRequest{VolumeIDs: ids})
+		if err != nil {
+			return nil, err
+		}
+		statuses := make(map[string]string)
+		for _, item := range items {
+			statuses[item.VolumeID] = string(item.Status)
+		}
+		return statuses, nil
+	})
+}
+
+// VolumePager pages through DescribeVolumes results.
+type VolumePager struct {
+	pager
+	page []DescribeVolumesItem
+}
+
+// NewVolumePager creates a pager for volumes matching filter.
+func (c *Client) NewVolumePager(filter DescribeVolumesRequest) *VolumePager {
+	p := &VolumePager{}
+	p.pager = newPager(filter.Offset, filter.Limit, func(ctx context.Context, offset, limit int) (int, int, error) {
+		filter.Offset, filter.Limit = offset, limit
+		p.page = nil
+		response, err := c.DescribeVolumes(ctx, &filter)
+		if err != nil {
+			return 0, 0, err
+		}
+		p.page = response.ItemSet
+		return len(response.ItemSet), response.TotalCount, nil
+	})
+	return p
+}
+
+// Page returns volumes in the page fetched by the last call to Next.
+func (p *VolumePager) Page() []DescribeVolumesItem {
+	return p.page
+}
+
+// ListAllVolumes returns all volumes matching filter, following all pages.
+func (c *Client) ListAllVolumes(ctx context.Context, filter DescribeVolumesRequest) ([]DescribeVolumesItem, error) {
+	var items []DescribeVolumesItem
+	p := c.NewVolumePager(filter)
+	for p.Next(ctx) {
+		items = append(items, p.Page()...)
+	}
+	return items, p.Err()
+}
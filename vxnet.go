@@ -4,6 +4,10 @@
 
 package anchnet
 
+import (
+	"context"
+)
+
 // Implements all anchnet vxnet related APIs.
 
 //
@@ -18,8 +22,18 @@ type DescribeVxnetsRequest struct {
 	Limit         int      `json:"limit,omitempty"`
 }
 
+func (DescribeVxnetsRequest) ActionName() string { return "DescribeVxnets" }
+
+// DescribeVxnets sends DescribeVxnetsRequest to anchnet.
+func (c *Client) DescribeVxnets(ctx context.Context, request *DescribeVxnetsRequest) (*DescribeVxnetsResponse, error) {
+	var response DescribeVxnetsResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type DescribeVxnetsResponse struct {
 	ResponseCommon `json:",inline"`
+	TotalCount     int                  `json:"total_count,omitempty"`
 	ItemSet        []DescribeVxnetsItem `json:"item_set,omitempty"`
 }
 
@@ -30,7 +44,7 @@ type DescribeVxnetsItem struct {
 	VxnetType   VxnetType                `json:"vxnet_type"` // Do not omit empty due to type 0
 	Systype     string                   `json:"systype,omitempty"`
 	Description string                   `json:"description,omitempty"`
-	CreateTime  string                   `json:"create_time,omitempty"`
+	CreateTime  Time                     `json:"create_time,omitempty"`
 	Router      []DescribeVxnetsRouter   `json:"router,omitempty"`
 	Instances   []DescribeVxnetsInstance `json:"instances,omitempty"`
 }
@@ -54,6 +68,20 @@ type CreateVxnetsRequest struct {
 	Count         int       `json:"count,omitempty"` // Number of network to create, default to 1
 }
 
+func (CreateVxnetsRequest) ActionName() string { return "CreateVxnets" }
+
+// Validate implements Validator.
+func (r CreateVxnetsRequest) Validate() error {
+	return requireOneOf(r.ActionName(), "VxnetType", r.VxnetType, VxnetTypePriv, VxnetTypePub)
+}
+
+// CreateVxnets sends CreateVxnetsRequest to anchnet.
+func (c *Client) CreateVxnets(ctx context.Context, request *CreateVxnetsRequest) (*CreateVxnetsResponse, error) {
+	var response CreateVxnetsResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type CreateVxnetsResponse struct {
 	ResponseCommon `json:",inline"`
 	JobID          string   `json:"job_id,omitempty"`
@@ -76,6 +104,20 @@ type DeleteVxnetsRequest struct {
 	VxnetIDs      []string `json:"vxnets,omitempty"` // IDs of networks to delete
 }
 
+func (DeleteVxnetsRequest) ActionName() string { return "DeleteVxnets" }
+
+// Validate implements Validator.
+func (r DeleteVxnetsRequest) Validate() error {
+	return requireIDs(r.ActionName(), "VxnetIDs", r.VxnetIDs)
+}
+
+// DeleteVxnets sends DeleteVxnetsRequest to anchnet.
+func (c *Client) DeleteVxnets(ctx context.Context, request *DeleteVxnetsRequest) (*DeleteVxnetsResponse, error) {
+	var response DeleteVxnetsResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type DeleteVxnetsResponse struct {
 	ResponseCommon `json:",inline"`
 	JobID          string `json:"job_id,omitempty"`
@@ -90,6 +132,23 @@ type JoinVxnetRequest struct {
 	VxnetID       string   `json:"vxnet,omitempty"`     // ID of the network to join to
 }
 
+func (JoinVxnetRequest) ActionName() string { return "JoinVxnet" }
+
+// Validate implements Validator.
+func (r JoinVxnetRequest) Validate() error {
+	return firstError(
+		requireIDs(r.ActionName(), "InstanceIDs", r.InstanceIDs),
+		requireID(r.ActionName(), "VxnetID", r.VxnetID),
+	)
+}
+
+// JoinVxnet sends JoinVxnetRequest to anchnet.
+func (c *Client) JoinVxnet(ctx context.Context, request *JoinVxnetRequest) (*JoinVxnetResponse, error) {
+	var response JoinVxnetResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type JoinVxnetResponse struct {
 	ResponseCommon `json:",inline"`
 	JobID          string `json:"job_id,omitempty"`
@@ -104,6 +163,23 @@ type LeaveVxnetRequest struct {
 	VxnetID       string   `json:"vxnet,omitempty"`     // ID of the network to leave from
 }
 
+func (LeaveVxnetRequest) ActionName() string { return "LeaveVxnet" }
+
+// Validate implements Validator.
+func (r LeaveVxnetRequest) Validate() error {
+	return firstError(
+		requireIDs(r.ActionName(), "InstanceIDs", r.InstanceIDs),
+		requireID(r.ActionName(), "VxnetID", r.VxnetID),
+	)
+}
+
+// LeaveVxnet sends LeaveVxnetRequest to anchnet.
+func (c *Client) LeaveVxnet(ctx context.Context, request *LeaveVxnetRequest) (*LeaveVxnetResponse, error) {
+	var response LeaveVxnetResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type LeaveVxnetResponse struct {
 	ResponseCommon `json:",inline"`
 	JobID          string `json:"job_id,omitempty"`
@@ -119,7 +195,58 @@ type ModifyVxnetAttributesRequest struct {
 	Description   string `json:"description,omitempty"`
 }
 
+func (ModifyVxnetAttributesRequest) ActionName() string { return "ModifyVxnetAttributes" }
+
+// Validate implements Validator.
+func (r ModifyVxnetAttributesRequest) Validate() error {
+	return requireID(r.ActionName(), "VxnetID", r.VxnetID)
+}
+
+// ModifyVxnetAttributes sends ModifyVxnetAttributesRequest to anchnet.
+func (c *Client) ModifyVxnetAttributes(ctx context.Context, request *ModifyVxnetAttributesRequest) (*ModifyVxnetAttributesResponse, error) {
+	var response ModifyVxnetAttributesResponse
+	err := c.SendRequestWithContext(ctx, request, &response)
+	return &response, err
+}
+
 type ModifyVxnetAttributesResponse struct {
 	ResponseCommon `json:",inline"`
 	JobID          string `json:"job_id,omitempty"`
 }
+
+// VxnetPager pages through DescribeVxnets results.
+type VxnetPager struct {
+	pager
+	page []DescribeVxnetsItem
+}
+
+// NewVxnetPager creates a pager for vxnets matching filter.
+func (c *Client) NewVxnetPager(filter DescribeVxnetsRequest) *VxnetPager {
+	p := &VxnetPager{}
+	p.pager = newPager(filter.Offset, filter.Limit, func(ctx context.Context, offset, limit int) (int, int, error) {
+		filter.Offset, filter.Limit = offset, limit
+		p.page = nil
+		response, err := c.DescribeVxnets(ctx, &filter)
+		if err != nil {
+			return 0, 0, err
+		}
+		p.page = response.ItemSet
+		return len(response.ItemSet), response.TotalCount, nil
+	})
+	return p
+}
+
+// Page returns vxnets in the page fetched by the last call to Next.
+func (p *VxnetPager) Page() []DescribeVxnetsItem {
+	return p.page
+}
+
+// ListAllVxnets returns all vxnets matching filter, following all pages.
+func (c *Client) ListAllVxnets(ctx context.Context, filter DescribeVxnetsRequest) ([]DescribeVxnetsItem, error) {
+	var items []DescribeVxnetsItem
+	p := c.NewVxnetPager(filter)
+	for p.Next(ctx) {
+		items = append(items, p.Page()...)
+	}
+	return items, p.Err()
+}
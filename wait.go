@@ -0,0 +1,148 @@
+// Copyright 2015 anchnet-go authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package anchnet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// WaitOptions controls how waiters (e.g. WaitJob) poll anchnet.
+type WaitOptions struct {
+	// Interval is the delay between the first two polls.
+	Interval time.Duration
+	// Backoff multiplies the delay after every poll, up to MaxInterval. Values
+	// not greater than 1 keep polling at Interval.
+	Backoff     float64
+	MaxInterval time.Duration
+	// Timeout bounds the total time to wait. Zero means waiting until ctx is done.
+	Timeout time.Duration
+}
+
+// DefaultWaitOptions is used by waiters when nil options are given.
+var DefaultWaitOptions = WaitOptions{
+	Interval:    3 * time.Second,
+	Backoff:     1.5,
+	MaxInterval: 30 * time.Second,
+}
+
+// poll calls condition until it returns true or an error. Transient errors (see
+// IsRetryable), e.g. a lost Describe response, don't stop polling. It returns ctx
+// error if ctx is done, or opts.Timeout elapsed before condition is met.
+func poll(ctx context.Context, opts *WaitOptions, condition func(ctx context.Context) (bool, error)) error {
+	if opts == nil {
+		opts = &DefaultWaitOptions
+	}
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = DefaultWaitOptions.Interval
+	}
+	var lastErr error
+	for {
+		done, err := condition(ctx)
+		if err != nil && !IsRetryable(err) || done {
+			return err
+		}
+		lastErr = err
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			if lastErr != nil {
+				return fmt.Errorf("%w, last error: %v", ctx.Err(), lastErr)
+			}
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		if opts.Backoff > 1 {
+			interval = time.Duration(float64(interval) * opts.Backoff)
+			if opts.MaxInterval > 0 && interval > opts.MaxInterval {
+				interval = opts.MaxInterval
+			}
+		}
+	}
+}
+
+// StatusError is returned by resource waiters (e.g. WaitInstanceStatus) when a
+// resource reaches a terminal status, e.g. suspended, other than the one waited for,
+// or when the resource is not found.
+type StatusError struct {
+	ResourceID string
+	Status     string // Current status of the resource, empty if not found
+	Expected   string // Status waited for
+}
+
+func (e *StatusError) Error() string {
+	if e.Status == "" {
+		return fmt.Sprintf("resource %v is not found, will never become %v", e.ResourceID, e.Expected)
+	}
+	return fmt.Sprintf("resource %v is %v, will never become %v", e.ResourceID, e.Status, e.Expected)
+}
+
+// waitStatus waits until all resources reach target status. describe returns the
+// current status of a list of resources, keyed by ID. It returns a *StatusError as
+// soon as any resource reaches one of the terminal statuses, or is missing from the
+// result. A missing resource is done however if target is a terminal status, e.g.
+// deleted, since resources disappear some time after they end.
+func waitStatus(ctx context.Context, ids []string, target string, terminal []string, opts *WaitOptions,
+	describe func(ctx context.Context, ids []string) (map[string]string, error)) error {
+	pending := make(map[string]bool)
+	for _, id := range ids {
+		pending[id] = true
+	}
+	err := poll(ctx, opts, func(ctx context.Context) (bool, error) {
+		var describeIDs []string
+		for id := range pending {
+			describeIDs = append(describeIDs, id)
+		}
+		sort.Strings(describeIDs)
+		statuses, err := describe(ctx, describeIDs)
+		if err != nil {
+			return false, err
+		}
+		for _, id := range describeIDs {
+			status, ok := statuses[id]
+			if !ok {
+				if isTerminal(target, terminal) {
+					delete(pending, id)
+					continue
+				}
+				return false, &StatusError{ResourceID: id, Expected: target}
+			}
+			if status == target {
+				delete(pending, id)
+				continue
+			}
+			if isTerminal(status, terminal) {
+				return false, &StatusError{ResourceID: id, Status: status, Expected: target}
+			}
+		}
+		return len(pending) == 0, nil
+	})
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return fmt.Errorf("error waiting for %v to become %v: %w", ids, target, err)
+	}
+	return err
+}
+
+func isTerminal(status string, terminal []string) bool {
+	for _, t := range terminal {
+		if status == t {
+			return true
+		}
+	}
+	return false
+}
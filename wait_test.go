@@ -0,0 +1,150 @@
+// Copyright 2015 anchnet-go authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package anchnet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/caicloud/anchnet-go/testutil"
+)
+
+// TestWaitInstanceStatus tests that instance waiter polls until all instances are
+// running, and returns early on terminal status or missing instances.
+func TestWaitInstanceStatus(t *testing.T) {
+	opts := &WaitOptions{Interval: time.Millisecond, Timeout: time.Second}
+
+	tests := []struct {
+		// Item set returned by each poll; the last one sticks.
+		polls            [][]DescribeInstancesItem
+		expectedError    error
+		expectedRequests int32
+	}{
+		{
+			polls: [][]DescribeInstancesItem{
+				{{InstanceID: "i-1", Status: InstanceStatusPending}, {InstanceID: "i-2", Status: InstanceStatusPending}},
+				{{InstanceID: "i-1", Status: InstanceStatusRunning}, {InstanceID: "i-2", Status: InstanceStatusPending}},
+				{{InstanceID: "i-2", Status: InstanceStatusRunning}},
+			},
+			expectedRequests: 3,
+		},
+		{
+			polls: [][]DescribeInstancesItem{
+				{{InstanceID: "i-1", Status: InstanceStatusPending}, {InstanceID: "i-2", Status: InstanceStatusPending}},
+				{{InstanceID: "i-1", Status: InstanceStatusTerminated}},
+			},
+			expectedError:    &StatusError{ResourceID: "i-1", Status: "terminated", Expected: "running"},
+			expectedRequests: 2,
+		},
+		{
+			polls: [][]DescribeInstancesItem{
+				{{InstanceID: "i-1", Status: InstanceStatusRunning}},
+			},
+			expectedError:    &StatusError{ResourceID: "i-2", Expected: "running"},
+			expectedRequests: 1,
+		},
+		{
+			polls: [][]DescribeInstancesItem{
+				{{InstanceID: "i-1", Status: InstanceStatusPending}, {InstanceID: "i-2", Status: InstanceStatusPending}},
+				{{InstanceID: "i-1", Status: InstanceStatusRunning}, {InstanceID: "i-2", Status: InstanceStatusSuspended}},
+			},
+			expectedError:    &StatusError{ResourceID: "i-2", Status: "suspended", Expected: "running"},
+			expectedRequests: 2,
+		},
+	}
+
+	for i, test := range tests {
+		var requests int32
+		testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			n := int(atomic.AddInt32(&requests, 1))
+			if n > len(test.polls) {
+				n = len(test.polls)
+			}
+			json.NewEncoder(w).Encode(DescribeInstancesResponse{ItemSet: test.polls[n-1]})
+		}))
+
+		c, err := NewClient(testServer.URL, &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"})
+		if err != nil {
+			t.Errorf("Unexpected non-nil error %v", err)
+		}
+
+		err = c.WaitInstanceStatus(context.Background(), []string{"i-1", "i-2"}, InstanceStatusRunning, opts)
+		if !reflect.DeepEqual(test.expectedError, err) {
+			t.Errorf("Test %d: expected error %v, got %v", i, test.expectedError, err)
+		}
+		if requests != test.expectedRequests {
+			t.Errorf("Test %d: expected %d requests, got %d", i, test.expectedRequests, requests)
+		}
+		testServer.Close()
+	}
+}
+
+// TestWaitVolumeStatus tests that volume waiter describes pending volumes in batch.
+func TestWaitVolumeStatus(t *testing.T) {
+	expectedJson := RemoveWhitespaces(`
+{
+  "volumes": ["vom-1"],
+  "limit": 50,
+  "token": "E5I9QKJF1O2B5PXE68LG",
+  "zone": "ac1",
+  "action": "DescribeVolumes"
+}
+`)
+
+	fakeResponse := RemoveWhitespaces(`
+{
+  "ret_code": 0,
+  "action": "DescribeVolumesResponse",
+  "item_set": [{"volume_id": "vom-1", "status": "in-use", "volume_type": "0"}],
+  "code": 0,
+  "total_count": 1
+}
+`)
+
+	testServer := httptest.NewServer(&testutil.FakeHandler{T: t, ExpectedJson: expectedJson, FakeResponse: fakeResponse})
+	defer testServer.Close()
+
+	c, err := NewClient(testServer.URL, &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"})
+	if err != nil {
+		t.Errorf("Unexpected non-nil error %v", err)
+	}
+
+	err = c.WaitVolumeStatus(context.Background(), []string{"vom-1"}, VolumeStatusInUse, nil)
+	if err != nil {
+		t.Errorf("Unexpected non-nil error %v", err)
+	}
+}
+
+// TestWaitInstanceStatusPaging tests that instance waiter follows pages when
+// anchnet caps page size below the number of instances.
+func TestWaitInstanceStatusPaging(t *testing.T) {
+	handler := &fakePagingHandler{items: 60, maxLimit: 20, total: func(n int) int { return n }}
+	testServer := httptest.NewServer(handler)
+	defer testServer.Close()
+
+	c, err := NewClient(testServer.URL, &AuthConfiguration{PublicKey: "E5I9QKJF1O2B5PXE68LG", PrivateKey: "secret"})
+	if err != nil {
+		t.Errorf("Unexpected non-nil error %v", err)
+	}
+
+	var ids []string
+	for i := 0; i < handler.items; i++ {
+		ids = append(ids, fmt.Sprintf("i-%d", i))
+	}
+	err = c.WaitInstanceStatus(context.Background(), ids, InstanceStatusRunning, nil)
+	if err != nil {
+		t.Errorf("Unexpected non-nil error %v", err)
+	}
+	if handler.requests != 3 {
+		t.Errorf("Expected 3 requests, got %d", handler.requests)
+	}
+}